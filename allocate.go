@@ -0,0 +1,59 @@
+package dskit
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+)
+
+func (r *repo[E]) AllocateIDs(ctx context.Context, parent *datastore.Key, n int) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "AllocateIDs", telemetry.Keys(n))
+	defer func() { end(span, err, out) }()
+
+	if n < 0 {
+		return nil, &q.ArgumentError{Argument: "n", Reason: "cannot be negative"}
+	}
+
+	if n == 0 {
+		return make([]*datastore.Key, 0), nil
+	}
+
+	keys, err := r.newKeys(ctx, parent, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.AllocateIDs(ctx, r.client, keys)
+}
+
+func (r *repo[E]) ReserveIDs(ctx context.Context, keys []*datastore.Key) (err error) {
+	ctx, span := r.start(ctx, nil, "ReserveIDs", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, nil) }()
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	for _, k := range keys {
+		if err := checkKind(k, r.kind); err != nil {
+			return err
+		}
+	}
+
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return err
+	}
+
+	return q.ReserveIDs(ctx, r.client, keys)
+}
+
+func (r *repo[E]) createKeys(ctx context.Context, ancestor *datastore.Key, n int) ([]*datastore.Key, error) {
+	keys, err := r.newKeys(ctx, ancestor, n)
+	if err != nil || !r.preallocate || n == 0 {
+		return keys, err
+	}
+
+	return q.AllocateIDs(ctx, r.client, keys)
+}
@@ -0,0 +1,119 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+func TestAllocateIDs(t *testing.T) {
+	ctx := context.Background()
+	parent := datastore.NameKey("Folder", "root", nil)
+
+	tests := []struct {
+		name    string
+		parent  *datastore.Key
+		n       int
+		wantErr error
+	}{
+		{name: "negative", n: -1, wantErr: q.ErrInvalidArgument},
+		{name: "zero", n: 0},
+		{name: "several", n: 3},
+		{name: "with parent", parent: parent, n: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			keys, err := fake.NewRepo[tenantNote](c, "Note").AllocateIDs(ctx, tt.parent, tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AllocateIDs() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if keys == nil || len(keys) != tt.n {
+				t.Fatalf("AllocateIDs() = %v, want %d keys", keys, tt.n)
+			}
+
+			if tt.n == 0 && c.Store().Calls("AllocateIds") != 0 {
+				t.Fatalf("AllocateIDs(0) issued an RPC")
+			}
+
+			for _, k := range keys {
+				if k.Incomplete() || k.Kind != "Note" || !k.Parent.Equal(tt.parent) {
+					t.Fatalf("AllocateIDs() key = %v, want a complete Note key under %v", k, tt.parent)
+				}
+			}
+		})
+	}
+}
+
+func TestReserveIDs(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		scope   string
+		keys    []*datastore.Key
+		wantErr error
+	}{
+		{name: "empty"},
+		{name: "reserved", keys: []*datastore.Key{datastore.IDKey("Note", 100, nil)}},
+		{name: "wrong kind", keys: []*datastore.Key{datastore.IDKey("Other", 100, nil)}, wantErr: q.ErrInvalidArgument},
+		{name: "nil key", keys: []*datastore.Key{nil}, wantErr: q.ErrInvalidArgument},
+		{
+			name:  "scoped namespace",
+			scope: "tenant-a",
+			keys:  []*datastore.Key{{Kind: "Note", ID: 100, Namespace: "tenant-a"}},
+		},
+		{
+			name:    "foreign namespace",
+			scope:   "tenant-a",
+			keys:    []*datastore.Key{{Kind: "Note", ID: 100, Namespace: "tenant-b"}},
+			wantErr: q.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			r := fake.NewRepo[tenantNote](c, "Note")
+			if tt.scope != "" {
+				r = r.WithNamespace(tt.scope)
+			}
+
+			err := r.ReserveIDs(ctx, tt.keys)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReserveIDs() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if want := len(tt.keys) > 0 && tt.wantErr == nil; (c.Store().Calls("ReserveIds") == 1) != want {
+				t.Fatalf("ReserveIds RPCs = %d, want reserved %v", c.Store().Calls("ReserveIds"), want)
+			}
+
+			if tt.wantErr != nil || len(tt.keys) == 0 {
+				return
+			}
+
+			keys, err := r.AllocateIDs(ctx, nil, 1)
+			if err != nil {
+				t.Fatalf("AllocateIDs() error = %v", err)
+			}
+
+			if keys[0].ID <= tt.keys[0].ID {
+				t.Fatalf("AllocateIDs() = %v after reserving %v", keys[0], tt.keys[0])
+			}
+		})
+	}
+}
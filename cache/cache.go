@@ -0,0 +1,16 @@
+package cache
+
+import (
+	"context"
+)
+
+type Entry[E any] struct {
+	Value   *E
+	Missing bool
+}
+
+type Cache[E any] interface {
+	Get(ctx context.Context, keys ...string) (map[string]Entry[E], error)
+	Set(ctx context.Context, entries map[string]Entry[E]) error
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+)
+
+const generationStripes = 256
+
+type generations [generationStripes]atomic.Uint64
+
+func (g *generations) of(id string) *atomic.Uint64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+
+	return &g[h.Sum32()%generationStripes]
+}
+
+func (g *generations) bump(ids ...string) {
+	for _, id := range ids {
+		g.of(id).Add(1)
+	}
+}
+
+func (g *generations) snapshot(ids []string) []uint64 {
+	out := make([]uint64, len(ids))
+
+	for i, id := range ids {
+		out[i] = g.of(id).Load()
+	}
+
+	return out
+}
+
+func (g *generations) changed(ids []string, seen []uint64) []string {
+	var out []string
+
+	for i, id := range ids {
+		if g.of(id).Load() != seen[i] {
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type LRU[E any] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	clock func() time.Time
+	items map[string]*list.Element
+	order *list.List
+}
+
+type lruItem[E any] struct {
+	key     string
+	entry   Entry[E]
+	expires time.Time
+}
+
+var _ Cache[struct{}] = (*LRU[struct{}])(nil)
+
+func NewLRU[E any](size int, ttl time.Duration) *LRU[E] {
+	return &LRU[E]{
+		size:  size,
+		ttl:   ttl,
+		clock: time.Now,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+func (c *LRU[E]) Get(_ context.Context, keys ...string) (map[string]Entry[E], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock()
+	found := make(map[string]Entry[E], len(keys))
+
+	for _, k := range keys {
+		el, ok := c.items[k]
+		if !ok {
+			continue
+		}
+
+		item := el.Value.(*lruItem[E])
+
+		if !item.expires.IsZero() && !now.Before(item.expires) {
+			c.remove(el)
+
+			continue
+		}
+
+		c.order.MoveToFront(el)
+		found[k] = item.entry
+	}
+
+	return found, nil
+}
+
+func (c *LRU[E]) Set(_ context.Context, entries map[string]Entry[E]) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+
+	if c.ttl > 0 {
+		expires = c.clock().Add(c.ttl)
+	}
+
+	for k, e := range entries {
+		if el, ok := c.items[k]; ok {
+			item := el.Value.(*lruItem[E])
+			item.entry = e
+			item.expires = expires
+			c.order.MoveToFront(el)
+
+			continue
+		}
+
+		c.items[k] = c.order.PushFront(&lruItem[E]{key: k, entry: e, expires: expires})
+	}
+
+	for c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *LRU[E]) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, k := range keys {
+		if el, ok := c.items[k]; ok {
+			c.remove(el)
+		}
+	}
+
+	return nil
+}
+
+func (c *LRU[E]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU[E]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruItem[E]).key)
+}
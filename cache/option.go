@@ -0,0 +1,32 @@
+package cache
+
+type Option func(*config)
+
+type config struct {
+	negative bool
+	cloner   any
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		negative: true,
+	}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+func WithNegativeCaching(enabled bool) Option {
+	return func(c *config) {
+		c.negative = enabled
+	}
+}
+
+func WithCloner[E any](clone func(*E) *E) Option {
+	return func(c *config) {
+		c.cloner = clone
+	}
+}
@@ -0,0 +1,233 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/huysamen/dskit/cache"
+)
+
+const (
+	markerValue   = 'v'
+	markerMissing = 'n'
+)
+
+type Adapter[E any] struct {
+	cache    Cache
+	codec    Codec
+	prefix   string
+	ttl      time.Duration
+	leaseTTL time.Duration
+	mu       sync.Mutex
+	leases   map[string]lease
+}
+
+type lease struct {
+	token   string
+	expires time.Time
+}
+
+var _ cache.Cache[struct{}] = (*Adapter[struct{}])(nil)
+
+func New[E any](c Cache, opts ...Option) *Adapter[E] {
+	cfg := newConfig(opts)
+
+	return &Adapter[E]{
+		cache:    c,
+		codec:    cfg.codec,
+		prefix:   cfg.prefix,
+		ttl:      cfg.ttl,
+		leaseTTL: cfg.leaseTTL,
+		leases:   make(map[string]lease),
+	}
+}
+
+func (a *Adapter[E]) Get(ctx context.Context, keys ...string) (map[string]cache.Entry[E], error) {
+	ids := make([]string, len(keys))
+
+	for i, k := range keys {
+		ids[i] = a.prefix + k
+	}
+
+	raw, err := a.cache.GetMulti(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]cache.Entry[E], len(raw))
+
+	var missing, corrupt []string
+
+	for i, k := range keys {
+		data, ok := raw[ids[i]]
+		if !ok {
+			missing = append(missing, ids[i])
+
+			continue
+		}
+
+		entry, err := a.decode(data)
+		if err != nil {
+			corrupt = append(corrupt, ids[i])
+
+			continue
+		}
+
+		found[k] = entry
+	}
+
+	if len(corrupt) > 0 {
+		if err := a.cache.DeleteMulti(ctx, corrupt); err != nil {
+			return found, nil
+		}
+
+		missing = append(missing, corrupt...)
+	}
+
+	a.lease(ctx, missing)
+
+	return found, nil
+}
+
+func (a *Adapter[E]) Set(ctx context.Context, entries map[string]cache.Entry[E]) error {
+	if a.leaseTTL <= 0 {
+		return a.setMulti(ctx, entries)
+	}
+
+	items := make(map[string]Leased, len(entries))
+
+	var errs []error
+
+	for k, e := range entries {
+		id := a.prefix + k
+
+		token, ok := a.take(id)
+		if !ok {
+			continue
+		}
+
+		data, err := a.encode(e)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		items[id] = Leased{Lease: token, Value: data}
+	}
+
+	if len(items) > 0 {
+		if _, err := a.cache.CompareAndSetMulti(ctx, items, a.ttl); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *Adapter[E]) setMulti(ctx context.Context, entries map[string]cache.Entry[E]) error {
+	items := make(map[string][]byte, len(entries))
+
+	for k, e := range entries {
+		data, err := a.encode(e)
+		if err != nil {
+			return err
+		}
+
+		items[a.prefix+k] = data
+	}
+
+	return a.cache.SetMulti(ctx, items, a.ttl)
+}
+
+func (a *Adapter[E]) Delete(ctx context.Context, keys ...string) error {
+	ids := make([]string, len(keys))
+
+	for i, k := range keys {
+		ids[i] = a.prefix + k
+		a.take(ids[i])
+	}
+
+	return a.cache.DeleteMulti(ctx, ids)
+}
+
+func (a *Adapter[E]) lease(ctx context.Context, ids []string) {
+	if a.leaseTTL <= 0 || len(ids) == 0 {
+		return
+	}
+
+	tokens, err := a.cache.LeaseMulti(ctx, ids, a.leaseTTL)
+	if err != nil || len(tokens) == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for k, l := range a.leases {
+		if now.After(l.expires) {
+			delete(a.leases, k)
+		}
+	}
+
+	for id, token := range tokens {
+		a.leases[id] = lease{token: token, expires: now.Add(a.leaseTTL)}
+	}
+}
+
+func (a *Adapter[E]) take(id string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	l, ok := a.leases[id]
+	if !ok {
+		return "", false
+	}
+
+	delete(a.leases, id)
+
+	if time.Now().After(l.expires) {
+		return "", false
+	}
+
+	return l.token, true
+}
+
+func (a *Adapter[E]) encode(e cache.Entry[E]) ([]byte, error) {
+	if e.Missing || e.Value == nil {
+		return []byte{markerMissing}, nil
+	}
+
+	data, err := a.codec.Marshal(e.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{markerValue}, data...), nil
+}
+
+func (a *Adapter[E]) decode(data []byte) (cache.Entry[E], error) {
+	if len(data) == 0 {
+		return cache.Entry[E]{}, errors.New("empty value")
+	}
+
+	switch data[0] {
+	case markerMissing:
+		return cache.Entry[E]{Missing: true}, nil
+	case markerValue:
+		v := new(E)
+
+		if err := a.codec.Unmarshal(data[1:], v); err != nil {
+			return cache.Entry[E]{}, err
+		}
+
+		return cache.Entry[E]{Value: v}, nil
+	default:
+		return cache.Entry[E]{}, fmt.Errorf("unknown marker %q", data[0])
+	}
+}
@@ -0,0 +1,201 @@
+package remote_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huysamen/dskit/cache"
+	"github.com/huysamen/dskit/cache/remote"
+	"github.com/huysamen/dskit/cache/resp"
+	"github.com/huysamen/dskit/cache/resp/resptest"
+)
+
+type widget struct {
+	Name string
+}
+
+type counting struct {
+	remote.Cache
+	leases, swaps atomic.Int64
+}
+
+func (c *counting) LeaseMulti(ctx context.Context, keys []string, ttl time.Duration) (map[string]string, error) {
+	c.leases.Add(1)
+
+	return c.Cache.LeaseMulti(ctx, keys, ttl)
+}
+
+func (c *counting) CompareAndSetMulti(ctx context.Context, items map[string]remote.Leased, ttl time.Duration) (map[string]bool, error) {
+	c.swaps.Add(1)
+
+	return c.Cache.CompareAndSetMulti(ctx, items, ttl)
+}
+
+func newAdapter(t *testing.T) (*counting, *remote.Adapter[widget]) {
+	t.Helper()
+
+	srv := resptest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client := resp.New(srv.Addr)
+	t.Cleanup(func() { _ = client.Close() })
+
+	c := &counting{Cache: client}
+
+	return c, remote.New[widget](c)
+}
+
+func TestAdapterReadThrough(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		keys       []string
+		raw        map[string][]byte
+		wantFound  int
+		wantLeases int64
+		wantSwaps  int64
+	}{
+		{name: "all missing", keys: []string{"a", "b", "c"}, wantLeases: 1, wantSwaps: 1},
+		{
+			name:       "corrupt entry is a miss",
+			keys:       []string{"a", "b"},
+			raw:        map[string][]byte{"dskit:a": []byte("vgarbage"), "dskit:b": {'x'}},
+			wantLeases: 1,
+			wantSwaps:  1,
+		},
+		{
+			name:      "all cached",
+			keys:      []string{"a"},
+			raw:       map[string][]byte{"dskit:a": {'n'}},
+			wantFound: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, a := newAdapter(t)
+
+			if err := c.SetMulti(ctx, tt.raw, time.Minute); err != nil {
+				t.Fatalf("SetMulti() error = %v", err)
+			}
+
+			found, err := a.Get(ctx, tt.keys...)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			if len(found) != tt.wantFound {
+				t.Fatalf("Get() found %d entries, want %d", len(found), tt.wantFound)
+			}
+
+			fill := make(map[string]cache.Entry[widget])
+
+			for _, k := range tt.keys {
+				if _, ok := found[k]; !ok {
+					fill[k] = cache.Entry[widget]{Value: &widget{Name: k}}
+				}
+			}
+
+			if err := a.Set(ctx, fill); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			if got := c.leases.Load(); got != tt.wantLeases {
+				t.Fatalf("LeaseMulti calls = %d, want %d", got, tt.wantLeases)
+			}
+
+			if got := c.swaps.Load(); got != tt.wantSwaps {
+				t.Fatalf("CompareAndSetMulti calls = %d, want %d", got, tt.wantSwaps)
+			}
+
+			found, err = a.Get(ctx, tt.keys...)
+			if err != nil {
+				t.Fatalf("Get() after Set error = %v", err)
+			}
+
+			if len(found) != len(tt.keys) {
+				t.Fatalf("Get() after Set found %d entries, want %d", len(found), len(tt.keys))
+			}
+
+			for k, e := range fill {
+				if found[k].Value == nil || found[k].Value.Name != e.Value.Name {
+					t.Fatalf("Get()[%q] = %+v, want %+v", k, found[k], e)
+				}
+			}
+		})
+	}
+}
+
+func TestAdapterLeases(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		between func(t *testing.T, other *remote.Adapter[widget])
+		want    string
+	}{
+		{
+			name: "concurrent reader does not fill",
+			between: func(t *testing.T, other *remote.Adapter[widget]) {
+				if _, err := other.Get(ctx, "a"); err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+
+				if err := other.Set(ctx, map[string]cache.Entry[widget]{"a": {Value: &widget{Name: "other"}}}); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			},
+			want: "first",
+		},
+		{
+			name: "invalidation drops the fill",
+			between: func(t *testing.T, other *remote.Adapter[widget]) {
+				if err := other.Delete(ctx, "a"); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, first := newAdapter(t)
+			other := remote.New[widget](c)
+
+			if _, err := first.Get(ctx, "a"); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			tt.between(t, other)
+
+			if err := first.Set(ctx, map[string]cache.Entry[widget]{"a": {Value: &widget{Name: "first"}}}); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			raw, err := c.GetMulti(ctx, []string{"dskit:a"})
+			if err != nil {
+				t.Fatalf("GetMulti() error = %v", err)
+			}
+
+			found, err := remote.New[widget](c, remote.WithLeaseTTL(0)).Get(ctx, "a")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			if tt.want == "" {
+				if len(raw) != 0 {
+					t.Fatalf("entry was filled after invalidation: %+v", found)
+				}
+
+				return
+			}
+
+			if found["a"].Value == nil || found["a"].Value.Name != tt.want {
+				t.Fatalf("Get()[a] = %+v, want %q", found["a"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package remote
+
+import (
+	"time"
+)
+
+type Option func(*config)
+
+type config struct {
+	codec    Codec
+	prefix   string
+	ttl      time.Duration
+	leaseTTL time.Duration
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		codec:    Gob{},
+		prefix:   "dskit:",
+		ttl:      time.Hour,
+		leaseTTL: time.Second,
+	}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+func WithCodec(codec Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+func WithTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.ttl = ttl
+	}
+}
+
+func WithLeaseTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.leaseTTL = ttl
+	}
+}
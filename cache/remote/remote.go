@@ -0,0 +1,19 @@
+package remote
+
+import (
+	"context"
+	"time"
+)
+
+type Cache interface {
+	GetMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+	SetMulti(ctx context.Context, items map[string][]byte, ttl time.Duration) error
+	DeleteMulti(ctx context.Context, keys []string) error
+	LeaseMulti(ctx context.Context, keys []string, ttl time.Duration) (map[string]string, error)
+	CompareAndSetMulti(ctx context.Context, items map[string]Leased, ttl time.Duration) (map[string]bool, error)
+}
+
+type Leased struct {
+	Lease string
+	Value []byte
+}
@@ -0,0 +1,492 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	q "github.com/huysamen/dskit/query"
+)
+
+type Repo[E any] struct {
+	dskit.Repo[E]
+	cache       Cache[E]
+	negative    bool
+	clone       func(*E) (*E, error)
+	generations *generations
+	counters    *counters
+}
+
+var _ dskit.Repo[struct{}] = (*Repo[struct{}])(nil)
+
+func NewRepo[E any](repo dskit.Repo[E], cache Cache[E], opts ...Option) *Repo[E] {
+	cfg := newConfig(opts)
+
+	clone := deepCopy[E]
+
+	if fn, ok := cfg.cloner.(func(*E) *E); ok {
+		clone = func(e *E) (*E, error) { return fn(e), nil }
+	}
+
+	return &Repo[E]{
+		Repo:        repo,
+		cache:       cache,
+		negative:    cfg.negative,
+		clone:       clone,
+		generations: &generations{},
+		counters:    &counters{},
+	}
+}
+
+func (r *Repo[E]) WithNamespace(namespace string) dskit.Repo[E] {
+	return &Repo[E]{
+		Repo:        r.Repo.WithNamespace(namespace),
+		cache:       r.cache,
+		negative:    r.negative,
+		clone:       r.clone,
+		generations: r.generations,
+		counters:    r.counters,
+	}
+}
+
+func (r *Repo[E]) Stats() Stats {
+	return r.counters.snapshot()
+}
+
+func cacheable(keys []*datastore.Key) bool {
+	for _, k := range keys {
+		if k == nil || k.Incomplete() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func deepCopy[E any](e *E) (*E, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, err
+	}
+
+	c := new(E)
+
+	if err := gob.NewDecoder(&buf).Decode(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func notFound() error {
+	return q.Classify(datastore.ErrNoSuchEntity)
+}
+
+func (r *Repo[E]) lookup(ctx context.Context, keys []*datastore.Key) ([]*E, []error, error) {
+	ids := make([]string, len(keys))
+
+	for i, k := range keys {
+		ids[i] = k.Encode()
+	}
+
+	cached, err := r.cache.Get(ctx, ids...)
+	if err != nil {
+		r.counters.errors.Add(1)
+
+		cached = nil
+	}
+
+	entities := make([]*E, len(keys))
+	errs := make([]error, len(keys))
+
+	var (
+		positions []int
+		missing   []*datastore.Key
+	)
+
+	for i, id := range ids {
+		entry, ok := cached[id]
+
+		if ok && !entry.Missing {
+			if value, err := r.clone(entry.Value); err == nil {
+				r.counters.hits.Add(1)
+				entities[i] = value
+
+				continue
+			}
+
+			ok = false
+		}
+
+		switch {
+		case !ok:
+			r.counters.misses.Add(1)
+
+			positions = append(positions, i)
+			missing = append(missing, keys[i])
+		default:
+			r.counters.hits.Add(1)
+			r.counters.negativeHits.Add(1)
+
+			errs[i] = notFound()
+		}
+	}
+
+	if len(missing) == 0 {
+		return entities, errs, nil
+	}
+
+	filling := make([]string, len(missing))
+
+	for j, i := range positions {
+		filling[j] = ids[i]
+	}
+
+	seen := r.generations.snapshot(filling)
+
+	loaded, statuses, err := r.Repo.ReadMultiPartial(ctx, missing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fill := make(map[string]Entry[E], len(missing))
+
+	for j, s := range statuses {
+		i := positions[j]
+
+		switch s.State {
+		case q.ReadFound:
+			entities[i] = loaded[j]
+
+			if value, err := r.clone(loaded[j]); err == nil {
+				fill[ids[i]] = Entry[E]{Value: value}
+			}
+		case q.ReadMissing:
+			errs[i] = s.Err
+
+			if r.negative {
+				fill[ids[i]] = Entry[E]{Missing: true}
+			}
+		case q.ReadFieldMismatch:
+			entities[i] = loaded[j]
+			errs[i] = s.Err
+		default:
+			errs[i] = s.Err
+		}
+	}
+
+	r.fill(ctx, fill, filling, seen)
+
+	return entities, errs, nil
+}
+
+func (r *Repo[E]) fill(ctx context.Context, fill map[string]Entry[E], ids []string, seen []uint64) {
+	for _, id := range r.generations.changed(ids, seen) {
+		delete(fill, id)
+	}
+
+	if len(fill) == 0 {
+		return
+	}
+
+	if err := r.cache.Set(ctx, fill); err != nil {
+		r.counters.errors.Add(1)
+
+		return
+	}
+
+	stale := slices.DeleteFunc(r.generations.changed(ids, seen), func(id string) bool {
+		_, filled := fill[id]
+
+		return !filled
+	})
+
+	if len(stale) == 0 {
+		return
+	}
+
+	if err := r.cache.Delete(ctx, stale...); err != nil {
+		r.counters.errors.Add(1)
+	}
+}
+
+func (r *Repo[E]) CheckKeys(ctx context.Context, keys ...*datastore.Key) error {
+	if c, ok := r.Repo.(dskit.KeyChecker); ok {
+		return c.CheckKeys(ctx, keys...)
+	}
+
+	return nil
+}
+
+func (r *Repo[E]) Read(ctx context.Context, key *datastore.Key) (*E, error) {
+	if !cacheable([]*datastore.Key{key}) {
+		return r.Repo.Read(ctx, key)
+	}
+
+	if err := r.CheckKeys(ctx, key); err != nil {
+		return new(E), err
+	}
+
+	entities, errs, err := r.lookup(ctx, []*datastore.Key{key})
+	if err != nil {
+		return new(E), err
+	}
+
+	if entities[0] == nil {
+		return new(E), errs[0]
+	}
+
+	return entities[0], errs[0]
+}
+
+func (r *Repo[E]) ReadMulti(ctx context.Context, keys []*datastore.Key) ([]*E, error) {
+	if len(keys) == 0 || !cacheable(keys) {
+		return r.Repo.ReadMulti(ctx, keys)
+	}
+
+	if err := r.CheckKeys(ctx, keys...); err != nil {
+		return nil, err
+	}
+
+	entities, errs, err := r.lookup(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range errs {
+		if e != nil {
+			return nil, &q.BatchError{MultiError: errs}
+		}
+	}
+
+	return entities, nil
+}
+
+func (r *Repo[E]) invalidate(ctx context.Context, keys ...*datastore.Key) {
+	ids := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if k != nil && !k.Incomplete() {
+			ids = append(ids, k.Encode())
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	r.counters.invalidations.Add(uint64(len(ids)))
+	r.generations.bump(ids...)
+
+	if err := r.cache.Delete(ctx, ids...); err != nil {
+		r.counters.errors.Add(1)
+	}
+}
+
+func (r *Repo[E]) invalidateTxn(txn q.Transaction, keys ...*datastore.Key) {
+	r.invalidate(context.Background(), keys...)
+
+	if n, ok := txn.(dskit.CommitNotifier); ok {
+		n.OnCommit(func() { r.invalidate(context.Background(), keys...) })
+	}
+}
+
+func (r *Repo[E]) invalidateFutures(txn dskit.Transaction, futures ...*dskit.FutureKey) {
+	n, ok := txn.(dskit.CommitNotifier)
+	if !ok {
+		return
+	}
+
+	n.OnCommit(func() {
+		keys := make([]*datastore.Key, len(futures))
+
+		for i, f := range futures {
+			keys[i] = f.Key()
+		}
+
+		r.invalidate(context.Background(), keys...)
+	})
+}
+
+func (r *Repo[E]) CreateFutureTxn(txn dskit.Transaction, ancestor *datastore.Key, entity *E) (*dskit.FutureKey, error) {
+	future, err := r.Repo.CreateFutureTxn(txn, ancestor, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateFutures(txn, future)
+
+	return future, nil
+}
+
+func (r *Repo[E]) CreateMultiFutureTxn(txn dskit.Transaction, ancestor *datastore.Key, entities []*E) ([]*dskit.FutureKey, error) {
+	futures, err := r.Repo.CreateMultiFutureTxn(txn, ancestor, entities)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateFutures(txn, futures...)
+
+	return futures, nil
+}
+
+func (r *Repo[E]) CreateWithKey(ctx context.Context, key *datastore.Key, entity *E) (*datastore.Key, error) {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.CreateWithKey(ctx, key, entity)
+}
+
+func (r *Repo[E]) CreateWithKeyTxn(txn q.Transaction, key *datastore.Key, entity *E) (*datastore.PendingKey, error) {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.CreateWithKeyTxn(txn, key, entity)
+}
+
+func (r *Repo[E]) CreateMultiWithKeys(ctx context.Context, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error) {
+	defer r.invalidate(ctx, keys...)
+
+	return r.Repo.CreateMultiWithKeys(ctx, keys, entities)
+}
+
+func (r *Repo[E]) CreateMultiWithKeysTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
+	defer r.invalidateTxn(txn, keys...)
+
+	return r.Repo.CreateMultiWithKeysTxn(txn, keys, entities)
+}
+
+func (r *Repo[E]) Update(ctx context.Context, key *datastore.Key, entity *E) error {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.Update(ctx, key, entity)
+}
+
+func (r *Repo[E]) UpdateTxn(txn q.Transaction, key *datastore.Key, entity *E) error {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.UpdateTxn(txn, key, entity)
+}
+
+func (r *Repo[E]) UpdateMulti(ctx context.Context, keys []*datastore.Key, entities []*E) error {
+	defer r.invalidate(ctx, keys...)
+
+	return r.Repo.UpdateMulti(ctx, keys, entities)
+}
+
+func (r *Repo[E]) UpdateMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) error {
+	defer r.invalidateTxn(txn, keys...)
+
+	return r.Repo.UpdateMultiTxn(txn, keys, entities)
+}
+
+func (r *Repo[E]) Upsert(ctx context.Context, key *datastore.Key, entity *E) (*datastore.Key, error) {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.Upsert(ctx, key, entity)
+}
+
+func (r *Repo[E]) UpsertTxn(txn q.Transaction, key *datastore.Key, entity *E) (*datastore.PendingKey, error) {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.UpsertTxn(txn, key, entity)
+}
+
+func (r *Repo[E]) UpsertMulti(ctx context.Context, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error) {
+	defer r.invalidate(ctx, keys...)
+
+	return r.Repo.UpsertMulti(ctx, keys, entities)
+}
+
+func (r *Repo[E]) UpsertMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
+	defer r.invalidateTxn(txn, keys...)
+
+	return r.Repo.UpsertMultiTxn(txn, keys, entities)
+}
+
+func (r *Repo[E]) Patch(ctx context.Context, key *datastore.Key, patch func(*E) error) (*E, error) {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.Patch(ctx, key, patch)
+}
+
+func (r *Repo[E]) PatchTxn(txn q.Transaction, key *datastore.Key, patch func(*E) error) (*E, error) {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.PatchTxn(txn, key, patch)
+}
+
+func (r *Repo[E]) PatchMulti(ctx context.Context, keys []*datastore.Key, patch func(*E) error) ([]*E, error) {
+	defer r.invalidate(ctx, keys...)
+
+	return r.Repo.PatchMulti(ctx, keys, patch)
+}
+
+func (r *Repo[E]) PatchMultiTxn(txn q.Transaction, keys []*datastore.Key, patch func(*E) error) ([]*E, error) {
+	defer r.invalidateTxn(txn, keys...)
+
+	return r.Repo.PatchMultiTxn(txn, keys, patch)
+}
+
+func (r *Repo[E]) PatchFields(ctx context.Context, key *datastore.Key, fields map[string]any) (*E, error) {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.PatchFields(ctx, key, fields)
+}
+
+func (r *Repo[E]) PatchFieldsTxn(txn q.Transaction, key *datastore.Key, fields map[string]any) (*E, error) {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.PatchFieldsTxn(txn, key, fields)
+}
+
+func (r *Repo[E]) Delete(ctx context.Context, key *datastore.Key) error {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.Delete(ctx, key)
+}
+
+func (r *Repo[E]) DeleteTxn(txn q.Transaction, key *datastore.Key) error {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.DeleteTxn(txn, key)
+}
+
+func (r *Repo[E]) DeleteMulti(ctx context.Context, keys []*datastore.Key) error {
+	defer r.invalidate(ctx, keys...)
+
+	return r.Repo.DeleteMulti(ctx, keys)
+}
+
+func (r *Repo[E]) DeleteMultiTxn(txn q.Transaction, keys []*datastore.Key) error {
+	defer r.invalidateTxn(txn, keys...)
+
+	return r.Repo.DeleteMultiTxn(txn, keys)
+}
+
+func (r *Repo[E]) Restore(ctx context.Context, key *datastore.Key) error {
+	defer r.invalidate(ctx, key)
+
+	return r.Repo.Restore(ctx, key)
+}
+
+func (r *Repo[E]) RestoreTxn(txn q.Transaction, key *datastore.Key) error {
+	defer r.invalidateTxn(txn, key)
+
+	return r.Repo.RestoreTxn(txn, key)
+}
+
+func (r *Repo[E]) Purge(ctx context.Context, olderThan time.Duration) (int, error) {
+	purger, ok := r.Repo.(dskit.KeyPurger)
+	if !ok {
+		return r.Repo.Purge(ctx, olderThan)
+	}
+
+	purged, err := purger.PurgeKeys(ctx, olderThan)
+	r.invalidate(ctx, purged...)
+
+	return len(purged), err
+}
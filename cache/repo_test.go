@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type profile struct {
+	Name      string
+	Tags      []string
+	Phones    []phone
+	Address   *address
+	DeletedAt *time.Time `dskit:"deletedAt"`
+}
+
+type address struct {
+	City string
+}
+
+type phone struct {
+	Number string
+}
+
+type recording[E any] struct {
+	Cache[E]
+	mu      sync.Mutex
+	deleted []string
+	onSet   func()
+}
+
+func (c *recording[E]) Set(ctx context.Context, entries map[string]Entry[E]) error {
+	if c.onSet != nil {
+		onSet := c.onSet
+		c.onSet = nil
+		onSet()
+	}
+
+	return c.Cache.Set(ctx, entries)
+}
+
+func (c *recording[E]) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	c.deleted = append(c.deleted, keys...)
+	c.mu.Unlock()
+
+	return c.Cache.Delete(ctx, keys...)
+}
+
+func (c *recording[E]) wasDeleted(key *datastore.Key) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Contains(c.deleted, key.Encode())
+}
+
+func newProfiles(t *testing.T, opts ...Option) (*fake.Client, *recording[profile], *Repo[profile]) {
+	t.Helper()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	rec := &recording[profile]{Cache: NewLRU[profile](100, 0)}
+	base := fake.NewRepo[profile](c, "Profile", dskit.WithSoftDelete[profile](), dskit.WithPreallocatedIDs[profile]())
+
+	return c, rec, NewRepo(base, rec, opts...)
+}
+
+func TestReadReturnsIsolatedCopies(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		mutate func(p *profile)
+	}{
+		{name: "slice", mutate: func(p *profile) { p.Tags[0] = "changed" }},
+		{name: "struct slice", mutate: func(p *profile) { p.Phones[0].Number = "changed" }},
+		{name: "pointer", mutate: func(p *profile) { p.Address.City = "changed" }},
+		{
+			name: "custom cloner",
+			opts: []Option{WithCloner(func(p *profile) *profile {
+				c := *p
+				c.Tags = slices.Clone(p.Tags)
+				c.Phones = slices.Clone(p.Phones)
+				c.Address = &address{City: p.Address.City}
+
+				return &c
+			})},
+			mutate: func(p *profile) { p.Tags[0], p.Phones[0].Number, p.Address.City = "changed", "changed", "changed" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, r := newProfiles(t, tt.opts...)
+
+			key, err := r.Create(ctx, nil, &profile{
+				Name:    "ada",
+				Tags:    []string{"admin"},
+				Phones:  []phone{{Number: "555"}},
+				Address: &address{City: "London"},
+			})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			first, err := r.Read(ctx, key)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			tt.mutate(first)
+
+			second, err := r.Read(ctx, key)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if second.Tags[0] != "admin" || second.Phones[0].Number != "555" || second.Address.City != "London" {
+				t.Fatalf("Read() = %+v, cached entry was modified through a previous result", second)
+			}
+
+			if s := r.Stats(); s.Hits != 1 {
+				t.Fatalf("Stats().Hits = %d, want 1", s.Hits)
+			}
+		})
+	}
+}
+
+func TestReadDropsFillRacingInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		negative bool
+		create   bool
+	}{
+		{name: "stale entity", create: true},
+		{name: "stale negative entry", negative: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, rec, r := newProfiles(t, WithNegativeCaching(tt.negative))
+
+			key := r.KeyFromName("ada", nil)
+
+			if tt.create {
+				if _, err := r.CreateWithKey(ctx, key, &profile{Name: "old"}); err != nil {
+					t.Fatalf("CreateWithKey() error = %v", err)
+				}
+			}
+
+			rec.onSet = func() {
+				if _, err := r.Upsert(ctx, key, &profile{Name: "new"}); err != nil {
+					t.Errorf("Upsert() error = %v", err)
+				}
+			}
+
+			_, _ = r.Read(ctx, key)
+
+			got, err := r.Read(ctx, key)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if got.Name != "new" {
+				t.Fatalf("Read().Name = %q, want %q", got.Name, "new")
+			}
+		})
+	}
+}
+
+func TestWritesInvalidate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		write func(t *testing.T, c *fake.Client, rec *recording[profile], r *Repo[profile]) *datastore.Key
+	}{
+		{
+			name: "CreateFutureTxn",
+			write: func(t *testing.T, c *fake.Client, _ *recording[profile], r *Repo[profile]) *datastore.Key {
+				var future *dskit.FutureKey
+
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) (err error) {
+					future, err = r.CreateFutureTxn(txn, nil, &profile{Name: "ada"})
+
+					return err
+				})
+				if err != nil {
+					t.Fatalf("CreateFutureTxn() error = %v", err)
+				}
+
+				return future.Key()
+			},
+		},
+		{
+			name: "CreateMultiFutureTxn",
+			write: func(t *testing.T, c *fake.Client, _ *recording[profile], r *Repo[profile]) *datastore.Key {
+				var futures []*dskit.FutureKey
+
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) (err error) {
+					futures, err = r.CreateMultiFutureTxn(txn, nil, []*profile{{Name: "ada"}, {Name: "bob"}})
+
+					return err
+				})
+				if err != nil {
+					t.Fatalf("CreateMultiFutureTxn() error = %v", err)
+				}
+
+				return futures[1].Key()
+			},
+		},
+		{
+			name: "Purge",
+			write: func(t *testing.T, _ *fake.Client, rec *recording[profile], r *Repo[profile]) *datastore.Key {
+				key, err := r.Create(ctx, nil, &profile{Name: "ada"})
+				if err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+
+				if err := r.Delete(ctx, key); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+
+				rec.deleted = nil
+
+				if n, err := r.Purge(ctx, -time.Hour); err != nil || n != 1 {
+					t.Fatalf("Purge() = %d, %v, want 1", n, err)
+				}
+
+				return key
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec, r := newProfiles(t)
+
+			key := tt.write(t, c, rec, r)
+
+			if key == nil || !rec.wasDeleted(key) {
+				t.Fatalf("%s did not invalidate %v", tt.name, key)
+			}
+		})
+	}
+}
+
+func TestReadChecksNamespaceBeforeCache(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		read func(r dskit.Repo[profile], key *datastore.Key) error
+	}{
+		{
+			name: "Read",
+			read: func(r dskit.Repo[profile], key *datastore.Key) error {
+				_, err := r.Read(ctx, key)
+
+				return err
+			},
+		},
+		{
+			name: "ReadMulti",
+			read: func(r dskit.Repo[profile], key *datastore.Key) error {
+				_, err := r.ReadMulti(ctx, []*datastore.Key{key})
+
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, r := newProfiles(t)
+
+			tenantB := r.WithNamespace("tenant-b")
+
+			key, err := tenantB.Create(ctx, nil, &profile{Name: "ada"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if err := tt.read(tenantB, key); err != nil {
+				t.Fatalf("%s() in own namespace error = %v", tt.name, err)
+			}
+
+			if err := tt.read(r.WithNamespace("tenant-a"), key); !errors.Is(err, q.ErrInvalidArgument) {
+				t.Fatalf("%s() across namespaces error = %v, want %v", tt.name, err, q.ErrInvalidArgument)
+			}
+
+			if s := r.Stats(); s.Hits != 0 {
+				t.Fatalf("Stats().Hits = %d, want 0", s.Hits)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/huysamen/dskit/cache/remote"
+)
+
+const (
+	markerData  = 'd'
+	markerLease = 'l'
+)
+
+var ErrClosed = errors.New("resp: client closed")
+
+type Client struct {
+	addr   string
+	cfg    *config
+	pool   chan *conn
+	mu     sync.Mutex
+	closed bool
+}
+
+type conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+	w  *bufio.Writer
+}
+
+var _ remote.Cache = (*Client)(nil)
+
+func New(addr string, opts ...Option) *Client {
+	cfg := newConfig(opts)
+
+	return &Client{
+		addr: addr,
+		cfg:  cfg,
+		pool: make(chan *conn, max(cfg.poolSize, 1)),
+	}
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+
+	for {
+		select {
+		case cn := <-c.pool:
+			_ = cn.nc.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Client) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	found := make(map[string][]byte, len(keys))
+
+	if len(keys) == 0 {
+		return found, nil
+	}
+
+	err := c.do(ctx, func(cn *conn) error {
+		replies, err := cn.pipeline(command("MGET", keys...))
+		if err != nil {
+			return err
+		}
+
+		values, ok := replies[0].([]any)
+		if !ok || len(values) != len(keys) {
+			return fmt.Errorf("%w: unexpected MGET reply %v", ErrProtocol, replies[0])
+		}
+
+		for i, v := range values {
+			if b, ok := v.([]byte); ok && len(b) > 0 && b[0] == markerData {
+				found[keys[i]] = b[1:]
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (c *Client) SetMulti(ctx context.Context, items map[string][]byte, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	return c.do(ctx, func(cn *conn) error {
+		cmds := make([][][]byte, 0, len(items))
+
+		for k, v := range items {
+			cmds = append(cmds, set(k, append([]byte{markerData}, v...), ttl))
+		}
+
+		_, err := cn.pipeline(cmds...)
+
+		return err
+	})
+}
+
+func (c *Client) DeleteMulti(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return c.do(ctx, func(cn *conn) error {
+		_, err := cn.pipeline(command("DEL", keys...))
+
+		return err
+	})
+}
+
+func (c *Client) LeaseMulti(ctx context.Context, keys []string, ttl time.Duration) (map[string]string, error) {
+	leased := make(map[string]string, len(keys))
+
+	if len(keys) == 0 {
+		return leased, nil
+	}
+
+	tokens := make([]string, len(keys))
+	cmds := make([][][]byte, len(keys))
+
+	for i, k := range keys {
+		token, err := newToken()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens[i] = token
+		cmds[i] = append(set(k, append([]byte{markerLease}, token...), ttl), []byte("NX"))
+	}
+
+	err := c.do(ctx, func(cn *conn) error {
+		replies, err := cn.pipeline(cmds...)
+		if err != nil {
+			return err
+		}
+
+		for i, r := range replies {
+			if r != nil {
+				leased[keys[i]] = tokens[i]
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return leased, nil
+}
+
+func (c *Client) CompareAndSetMulti(ctx context.Context, items map[string]remote.Leased, ttl time.Duration) (map[string]bool, error) {
+	swapped := make(map[string]bool, len(items))
+
+	if len(items) == 0 {
+		return swapped, nil
+	}
+
+	keys := make([]string, 0, len(items))
+
+	for k := range items {
+		keys = append(keys, k)
+	}
+
+	err := c.do(ctx, func(cn *conn) error {
+		for range len(items) {
+			held, err := cn.held(keys, items)
+			if err != nil || len(held) == 0 {
+				return err
+			}
+
+			cmds := make([][][]byte, 0, len(held)+2)
+			cmds = append(cmds, command("MULTI"))
+
+			for _, k := range held {
+				cmds = append(cmds, set(k, append([]byte{markerData}, items[k].Value...), ttl))
+			}
+
+			replies, err := cn.pipeline(append(cmds, command("EXEC"))...)
+			if err != nil {
+				return err
+			}
+
+			if exec, _ := replies[len(replies)-1].([]any); exec != nil {
+				for _, k := range held {
+					swapped[k] = true
+				}
+
+				return nil
+			}
+
+			keys = held
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return swapped, nil
+}
+
+func (cn *conn) held(keys []string, items map[string]remote.Leased) ([]string, error) {
+	replies, err := cn.pipeline(command("WATCH", keys...), command("MGET", keys...))
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := replies[1].([]any)
+	if !ok || len(values) != len(keys) {
+		return nil, fmt.Errorf("%w: unexpected MGET reply %v", ErrProtocol, replies[1])
+	}
+
+	var held []string
+
+	for i, v := range values {
+		current, _ := v.([]byte)
+
+		if bytes.Equal(current, append([]byte{markerLease}, items[keys[i]].Lease...)) {
+			held = append(held, keys[i])
+		}
+	}
+
+	if len(held) == 0 {
+		_, err := cn.pipeline(command("UNWATCH"))
+
+		return nil, err
+	}
+
+	return held, nil
+}
+
+func (c *Client) do(ctx context.Context, fn func(cn *conn) error) error {
+	cn, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		err = cn.nc.SetDeadline(deadline)
+	} else {
+		err = cn.nc.SetDeadline(time.Time{})
+	}
+
+	if err == nil {
+		err = fn(cn)
+	}
+
+	c.release(cn, err)
+
+	return err
+}
+
+func (c *Client) acquire(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+
+	if closed {
+		return nil, ErrClosed
+	}
+
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+	}
+
+	d := net.Dialer{Timeout: c.cfg.dialTimeout}
+
+	nc, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{nc: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	var setup [][][]byte
+
+	if c.cfg.password != "" {
+		setup = append(setup, command("AUTH", c.cfg.password))
+	}
+
+	if c.cfg.database != 0 {
+		setup = append(setup, command("SELECT", strconv.Itoa(c.cfg.database)))
+	}
+
+	if len(setup) > 0 {
+		if _, err := cn.pipeline(setup...); err != nil {
+			_ = nc.Close()
+
+			return nil, err
+		}
+	}
+
+	return cn, nil
+}
+
+func (c *Client) release(cn *conn, err error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+
+	if err != nil || closed {
+		_ = cn.nc.Close()
+
+		return
+	}
+
+	select {
+	case c.pool <- cn:
+	default:
+		_ = cn.nc.Close()
+	}
+}
+
+func (cn *conn) pipeline(cmds ...[][]byte) ([]any, error) {
+	for _, cmd := range cmds {
+		if err := WriteCommand(cn.w, cmd...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]any, len(cmds))
+
+	var errs []error
+
+	for i := range replies {
+		v, err := ReadValue(cn.r)
+		if err != nil {
+			return nil, err
+		}
+
+		if e, ok := v.(Error); ok {
+			errs = append(errs, e)
+		}
+
+		replies[i] = v
+	}
+
+	return replies, errors.Join(errs...)
+}
+
+func command(name string, args ...string) [][]byte {
+	cmd := make([][]byte, 0, len(args)+1)
+	cmd = append(cmd, []byte(name))
+
+	for _, a := range args {
+		cmd = append(cmd, []byte(a))
+	}
+
+	return cmd
+}
+
+func set(key string, value []byte, ttl time.Duration) [][]byte {
+	cmd := [][]byte{[]byte("SET"), []byte(key), value}
+
+	if ttl > 0 {
+		cmd = append(cmd, []byte("PX"), []byte(strconv.FormatInt(max(ttl.Milliseconds(), 1), 10)))
+	}
+
+	return cmd
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,97 @@
+package resp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/huysamen/dskit/cache/remote"
+	"github.com/huysamen/dskit/cache/resp"
+	"github.com/huysamen/dskit/cache/resp/resptest"
+)
+
+func TestCompareAndSetMulti(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		keys    []string
+		between func(t *testing.T, c *resp.Client)
+		want    map[string]bool
+	}{
+		{
+			name: "all leases held",
+			keys: []string{"a", "b", "c"},
+			want: map[string]bool{"a": true, "b": true, "c": true},
+		},
+		{
+			name: "lost lease skips only that key",
+			keys: []string{"a", "b"},
+			between: func(t *testing.T, c *resp.Client) {
+				if err := c.DeleteMulti(ctx, []string{"b"}); err != nil {
+					t.Fatalf("DeleteMulti() error = %v", err)
+				}
+			},
+			want: map[string]bool{"a": true},
+		},
+		{
+			name: "overwritten lease",
+			keys: []string{"a"},
+			between: func(t *testing.T, c *resp.Client) {
+				if err := c.SetMulti(ctx, map[string][]byte{"a": []byte("fresh")}, time.Minute); err != nil {
+					t.Fatalf("SetMulti() error = %v", err)
+				}
+			},
+			want: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := resptest.NewServer()
+			t.Cleanup(srv.Close)
+
+			c := resp.New(srv.Addr)
+			t.Cleanup(func() { _ = c.Close() })
+
+			tokens, err := c.LeaseMulti(ctx, tt.keys, time.Minute)
+			if err != nil || len(tokens) != len(tt.keys) {
+				t.Fatalf("LeaseMulti() = %v, %v, want %d leases", tokens, err, len(tt.keys))
+			}
+
+			if again, err := c.LeaseMulti(ctx, tt.keys, time.Minute); err != nil || len(again) != 0 {
+				t.Fatalf("LeaseMulti() on held keys = %v, %v, want none", again, err)
+			}
+
+			if tt.between != nil {
+				tt.between(t, c)
+			}
+
+			items := make(map[string]remote.Leased, len(tokens))
+
+			for k, token := range tokens {
+				items[k] = remote.Leased{Lease: token, Value: []byte("value-" + k)}
+			}
+
+			swapped, err := c.CompareAndSetMulti(ctx, items, time.Minute)
+			if err != nil {
+				t.Fatalf("CompareAndSetMulti() error = %v", err)
+			}
+
+			if len(swapped) != len(tt.want) {
+				t.Fatalf("CompareAndSetMulti() = %v, want %v", swapped, tt.want)
+			}
+
+			got, err := c.GetMulti(ctx, tt.keys)
+			if err != nil {
+				t.Fatalf("GetMulti() error = %v", err)
+			}
+
+			for _, k := range tt.keys {
+				if tt.want[k] != swapped[k] || tt.want[k] != (string(got[k]) == "value-"+k) {
+					t.Fatalf("key %q: swapped = %v, stored = %q, want swapped %v", k, swapped[k], got[k], tt.want[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package resp
+
+import (
+	"time"
+)
+
+type Option func(*config)
+
+type config struct {
+	password    string
+	database    int
+	poolSize    int
+	dialTimeout time.Duration
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		poolSize:    8,
+		dialTimeout: 5 * time.Second,
+	}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+func WithPassword(password string) Option {
+	return func(c *config) {
+		c.password = password
+	}
+}
+
+func WithDatabase(database int) Option {
+	return func(c *config) {
+		c.database = database
+	}
+}
+
+func WithPoolSize(size int) Option {
+	return func(c *config) {
+		c.poolSize = size
+	}
+}
+
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.dialTimeout = timeout
+	}
+}
@@ -0,0 +1,166 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var ErrProtocol = errors.New("resp: protocol error")
+
+type Error string
+
+func (e Error) Error() string {
+	return "resp: " + string(e)
+}
+
+func WriteCommand(w *bufio.Writer, args ...[]byte) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+
+	for _, a := range args {
+		if err := WriteBulk(w, a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func WriteBulk(w *bufio.Writer, b []byte) error {
+	if b == nil {
+		_, err := w.WriteString("$-1\r\n")
+
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "$%d\r\n", len(b)); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+
+	_, err := w.WriteString("\r\n")
+
+	return err
+}
+
+func WriteValue(w *bufio.Writer, v any) error {
+	var err error
+
+	switch v := v.(type) {
+	case nil:
+		_, err = w.WriteString("$-1\r\n")
+	case Error:
+		_, err = fmt.Fprintf(w, "-%s\r\n", string(v))
+	case Status:
+		_, err = fmt.Fprintf(w, "+%s\r\n", string(v))
+	case int64:
+		_, err = fmt.Fprintf(w, ":%d\r\n", v)
+	case []byte:
+		err = WriteBulk(w, v)
+	case []any:
+		if v == nil {
+			_, err = w.WriteString("*-1\r\n")
+
+			break
+		}
+
+		if _, err = fmt.Fprintf(w, "*%d\r\n", len(v)); err != nil {
+			return err
+		}
+
+		for _, e := range v {
+			if err = WriteValue(w, e); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: cannot encode %T", ErrProtocol, v)
+	}
+
+	return err
+}
+
+type Status string
+
+func ReadValue(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, fmt.Errorf("%w: empty line", ErrProtocol)
+	}
+
+	switch line[0] {
+	case '+':
+		return Status(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrProtocol, err)
+		}
+
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrProtocol, err)
+		}
+
+		if n < 0 {
+			return nil, nil
+		}
+
+		b := make([]byte, n+2)
+
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrProtocol, err)
+		}
+
+		if n < 0 {
+			return []any(nil), nil
+		}
+
+		values := make([]any, n)
+
+		for i := range values {
+			if values[i], err = ReadValue(r); err != nil {
+				return nil, err
+			}
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected type %q", ErrProtocol, line[0])
+	}
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("%w: malformed line", ErrProtocol)
+	}
+
+	return line[:len(line)-2], nil
+}
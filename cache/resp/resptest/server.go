@@ -0,0 +1,377 @@
+package resptest
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huysamen/dskit/cache/resp"
+)
+
+type Server struct {
+	Addr     string
+	listener net.Listener
+	mu       sync.Mutex
+	items    map[string]item
+	versions map[string]uint64
+	version  uint64
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+type item struct {
+	value   []byte
+	expires time.Time
+}
+
+type session struct {
+	watched map[string]uint64
+	queue   [][][]byte
+	multi   bool
+}
+
+func NewServer() *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("resptest: failed to listen: " + err.Error())
+	}
+
+	s := &Server{
+		Addr:     l.Addr().String(),
+		listener: l,
+		items:    make(map[string]item),
+		versions: make(map[string]uint64),
+		conns:    make(map[net.Conn]struct{}),
+		now:      time.Now,
+	}
+
+	s.wg.Add(1)
+
+	go s.serve()
+
+	return s
+}
+
+func (s *Server) Close() {
+	_ = s.listener.Close()
+
+	s.mu.Lock()
+
+	for c := range s.conns {
+		_ = c.Close()
+	}
+
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+
+	for k := range s.items {
+		if _, ok := s.lookup(k); ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer s.wg.Done()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+
+		_ = c.Close()
+	}()
+
+	r, w := bufio.NewReader(c), bufio.NewWriter(c)
+	sess := &session{}
+
+	for {
+		v, err := resp.ReadValue(r)
+		if err != nil {
+			return
+		}
+
+		cmd, ok := args(v)
+		if !ok {
+			_ = resp.WriteValue(w, resp.Error("ERR protocol error"))
+		} else if err := resp.WriteValue(w, s.dispatch(sess, cmd)); err != nil {
+			return
+		}
+
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func args(v any) ([][]byte, bool) {
+	values, ok := v.([]any)
+	if !ok || len(values) == 0 {
+		return nil, false
+	}
+
+	cmd := make([][]byte, len(values))
+
+	for i, a := range values {
+		b, ok := a.([]byte)
+		if !ok {
+			return nil, false
+		}
+
+		cmd[i] = b
+	}
+
+	return cmd, true
+}
+
+func (s *Server) dispatch(sess *session, cmd [][]byte) any {
+	name := strings.ToUpper(string(cmd[0]))
+
+	if sess.multi {
+		switch name {
+		case "EXEC":
+			return s.exec(sess)
+		case "DISCARD":
+			sess.multi, sess.queue, sess.watched = false, nil, nil
+
+			return resp.Status("OK")
+		case "MULTI", "WATCH":
+			return resp.Error("ERR " + name + " inside MULTI is not allowed")
+		default:
+			sess.queue = append(sess.queue, cmd)
+
+			return resp.Status("QUEUED")
+		}
+	}
+
+	switch name {
+	case "MULTI":
+		sess.multi = true
+
+		return resp.Status("OK")
+	case "EXEC", "DISCARD":
+		return resp.Error("ERR " + name + " without MULTI")
+	case "WATCH":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if sess.watched == nil {
+			sess.watched = make(map[string]uint64)
+		}
+
+		for _, k := range cmd[1:] {
+			s.lookup(string(k))
+			sess.watched[string(k)] = s.versions[string(k)]
+		}
+
+		return resp.Status("OK")
+	case "UNWATCH":
+		sess.watched = nil
+
+		return resp.Status("OK")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.apply(name, cmd)
+}
+
+func (s *Server) exec(sess *session) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, watched := sess.queue, sess.watched
+	sess.multi, sess.queue, sess.watched = false, nil, nil
+
+	for k, v := range watched {
+		s.lookup(k)
+
+		if s.versions[k] != v {
+			return []any(nil)
+		}
+	}
+
+	replies := make([]any, len(queue))
+
+	for i, cmd := range queue {
+		replies[i] = s.apply(strings.ToUpper(string(cmd[0])), cmd)
+	}
+
+	return replies
+}
+
+func (s *Server) apply(name string, cmd [][]byte) any {
+	switch name {
+	case "PING":
+		return resp.Status("PONG")
+	case "AUTH", "SELECT":
+		return resp.Status("OK")
+	case "FLUSHALL", "FLUSHDB":
+		for k := range s.items {
+			s.remove(k)
+		}
+
+		return resp.Status("OK")
+	case "GET":
+		if len(cmd) != 2 {
+			return arity(name)
+		}
+
+		it, ok := s.lookup(string(cmd[1]))
+		if !ok {
+			return nil
+		}
+
+		return it.value
+	case "MGET":
+		if len(cmd) < 2 {
+			return arity(name)
+		}
+
+		values := make([]any, len(cmd)-1)
+
+		for i, k := range cmd[1:] {
+			if it, ok := s.lookup(string(k)); ok {
+				values[i] = it.value
+			}
+		}
+
+		return values
+	case "SET":
+		return s.set(cmd)
+	case "DEL":
+		if len(cmd) < 2 {
+			return arity(name)
+		}
+
+		var n int64
+
+		for _, k := range cmd[1:] {
+			if _, ok := s.lookup(string(k)); ok {
+				s.remove(string(k))
+				n++
+			}
+		}
+
+		return n
+	default:
+		return resp.Error("ERR unknown command '" + name + "'")
+	}
+}
+
+func (s *Server) set(cmd [][]byte) any {
+	if len(cmd) < 3 {
+		return arity("SET")
+	}
+
+	key, value := string(cmd[1]), cmd[2]
+
+	var (
+		expires time.Time
+		nx, xx  bool
+	)
+
+	for i := 3; i < len(cmd); i++ {
+		switch opt := strings.ToUpper(string(cmd[i])); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 >= len(cmd) {
+				return resp.Error("ERR syntax error")
+			}
+
+			n, err := strconv.ParseInt(string(cmd[i+1]), 10, 64)
+			if err != nil || n <= 0 {
+				return resp.Error("ERR invalid expire time in 'set' command")
+			}
+
+			unit := time.Millisecond
+			if opt == "EX" {
+				unit = time.Second
+			}
+
+			expires = s.now().Add(time.Duration(n) * unit)
+			i++
+		default:
+			return resp.Error("ERR syntax error")
+		}
+	}
+
+	_, exists := s.lookup(key)
+
+	if (nx && exists) || (xx && !exists) {
+		return nil
+	}
+
+	s.items[key] = item{value: append([]byte(nil), value...), expires: expires}
+	s.touch(key)
+
+	return resp.Status("OK")
+}
+
+func (s *Server) lookup(key string) (item, bool) {
+	it, ok := s.items[key]
+	if !ok {
+		return item{}, false
+	}
+
+	if !it.expires.IsZero() && !s.now().Before(it.expires) {
+		s.remove(key)
+
+		return item{}, false
+	}
+
+	return it, true
+}
+
+func (s *Server) remove(key string) {
+	delete(s.items, key)
+	s.touch(key)
+}
+
+func (s *Server) touch(key string) {
+	s.version++
+	s.versions[key] = s.version
+}
+
+func arity(name string) any {
+	return resp.Error("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+}
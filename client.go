@@ -7,51 +7,118 @@ import (
 
 	"cloud.google.com/go/compute/metadata"
 	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/api/option"
 )
 
 type Client interface {
 	Client() *datastore.Client
 	RunInTransaction(ctx context.Context, f func(txn Transaction) error, opts ...datastore.TransactionOption) (*datastore.Commit, error)
+	RunReadOnly(ctx context.Context, f func(txn Transaction) error, opts ...q.ReadOption) error
 }
 
 type client struct {
-	client *datastore.Client
+	client    *datastore.Client
+	retry     RetryPolicy
+	telemetry *telemetry.Telemetry
 }
 
 func (c *client) Client() *datastore.Client {
 	return c.client
 }
 
-func (c *client) RunInTransaction(ctx context.Context, f func(txn Transaction) error, opts ...datastore.TransactionOption) (*datastore.Commit, error) {
+func (c *client) Telemetry() *telemetry.Telemetry {
+	if c == nil {
+		return nil
+	}
+
+	return c.telemetry
+}
+
+func (c *client) RunInTransaction(ctx context.Context, f func(txn Transaction) error, opts ...datastore.TransactionOption) (_ *datastore.Commit, err error) {
+	ctx, span := c.telemetry.Start(ctx, "RunInTransaction")
+	defer func() { span.End(err, q.ErrorClass(err), nil) }()
+
+	for attempt := 1; ; attempt++ {
+		commit, err := c.runInTransaction(ctx, f, opts...)
+		span.SetAttributes(attribute.Int("dskit.transaction.attempts", attempt))
+
+		if err == nil {
+			return commit, nil
+		}
+
+		if attempt >= c.retry.MaxAttempts || !c.retry.retryable(err) {
+			return nil, err
+		}
+
+		delay := c.retry.backoff(attempt)
+
+		span.AddEvent("retry",
+			attribute.Int("dskit.transaction.attempt", attempt),
+			telemetry.ErrorTypeKey.String(q.ErrorClass(err)),
+			attribute.Float64("dskit.retry.delay", delay.Seconds()),
+		)
+
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(attempt, err, delay)
+		}
+
+		if sErr := sleep(ctx, delay); sErr != nil {
+			return nil, errors.Join(err, sErr)
+		}
+	}
+}
+
+func (c *client) RunReadOnly(ctx context.Context, f func(txn Transaction) error, opts ...q.ReadOption) (err error) {
+	ctx, span := c.telemetry.Start(ctx, "RunReadOnly")
+	defer func() { span.End(err, q.ErrorClass(err), nil) }()
+
+	_, err = c.RunInTransaction(ctx, f, q.ReadOnlyOptions(opts...)...)
+
+	return err
+}
+
+func (c *client) runInTransaction(ctx context.Context, f func(txn Transaction) error, opts ...datastore.TransactionOption) (_ *datastore.Commit, err error) {
+	id := telemetry.TransactionID()
+
+	ctx, span := c.telemetry.Start(ctx, "Transaction", id)
+	defer func() { span.End(err, q.ErrorClass(err), nil) }()
+
 	tx, err := c.client.NewTransaction(ctx, opts...)
 	if err != nil {
-		return nil, err
+		return nil, q.Classify(err)
 	}
 
-	err = f(NewTransaction(tx))
+	t := newTransaction(ctx, tx, c.telemetry.With(id))
+
+	err = f(t)
 	if err != nil {
-		rErr := tx.Rollback()
+		rErr := t.Rollback()
 		if rErr != nil {
 			return nil, errors.Join(err, rErr)
 		}
 		return nil, err
 	}
 
-	return tx.Commit()
+	return t.Commit()
 }
 
-func NewClient(ctx context.Context, databaseID string, options ...option.ClientOption) (Client, error) {
+func NewClient(ctx context.Context, databaseID string, options ...Option) (Client, error) {
 	var opts []option.ClientOption
-	var projectID string
 	var err error
 
-	opts = append(opts, options...)
+	cfg := newConfig(options)
+	opts = append(opts, cfg.clientOptions...)
+	projectID := cfg.projectID
 
 	if os.Getenv("DATASTORE_EMULATOR_HOST") == "" {
-		if os.Getenv("GCP_PROJECT_ID") != "" {
+		switch {
+		case projectID != "":
+		case os.Getenv("GCP_PROJECT_ID") != "":
 			projectID = os.Getenv("GCP_PROJECT_ID")
-		} else {
+		default:
 			projectID, err = metadata.ProjectIDWithContext(ctx)
 			if err != nil {
 				return nil, err
@@ -62,7 +129,9 @@ func NewClient(ctx context.Context, databaseID string, options ...option.ClientO
 			opts = append(opts, option.WithCredentialsFile(credentialsFile))
 		}
 	} else {
-		projectID = os.Getenv("GCP_PROJECT_ID")
+		if projectID == "" {
+			projectID = os.Getenv("GCP_PROJECT_ID")
+		}
 
 		if projectID == "" {
 			projectID = os.Getenv("DATASTORE_PROJECT_ID")
@@ -85,5 +154,9 @@ func NewClient(ctx context.Context, databaseID string, options ...option.ClientO
 		c, err = datastore.NewClient(ctx, projectID, opts...)
 	}
 
-	return &client{client: c}, err
+	return &client{
+		client:    c,
+		retry:     cfg.retry,
+		telemetry: telemetry.New(cfg.tracerProvider, cfg.meterProvider),
+	}, err
 }
@@ -0,0 +1,259 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+func TestCreateFutureResolvesOnCommit(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	tests := []struct {
+		name        string
+		preallocate bool
+		n           int
+		fail        error
+	}{
+		{name: "single", n: 1},
+		{name: "single preallocated", preallocate: true, n: 1},
+		{name: "multi", n: 3},
+		{name: "multi preallocated", preallocate: true, n: 3},
+		{name: "rolled back preallocated", preallocate: true, n: 2, fail: errAbort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			var opts []dskit.RepoOption[tenantNote]
+			if tt.preallocate {
+				opts = append(opts, dskit.WithPreallocatedIDs[tenantNote]())
+			}
+
+			r := fake.NewRepo[tenantNote](c, "Note", opts...)
+
+			var futures []*dskit.FutureKey
+
+			_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+				futures = nil
+
+				if tt.n == 1 {
+					f, err := r.CreateFutureTxn(txn, nil, &tenantNote{Text: "one"})
+					if err != nil {
+						return err
+					}
+
+					futures = append(futures, f)
+				} else {
+					entities := make([]*tenantNote, tt.n)
+					for i := range entities {
+						entities[i] = &tenantNote{Text: "many"}
+					}
+
+					fs, err := r.CreateMultiFutureTxn(txn, nil, entities)
+					if err != nil {
+						return err
+					}
+
+					futures = fs
+				}
+
+				for i, f := range futures {
+					if f.Resolved() {
+						t.Errorf("future %d resolved before commit: %v", i, f.Key())
+					}
+
+					if tt.preallocate == (f.Key() == nil) || tt.preallocate && f.Key().Incomplete() {
+						t.Errorf("future %d key before commit = %v, want allocated %v", i, f.Key(), tt.preallocate)
+					}
+				}
+
+				return tt.fail
+			})
+			if !errors.Is(err, tt.fail) {
+				t.Fatalf("RunInTransaction() error = %v, want %v", err, tt.fail)
+			}
+
+			for i, f := range futures {
+				if tt.fail != nil {
+					if f.Resolved() || f.Key() != nil || !errors.Is(f.Err(), dskit.ErrRolledBack) {
+						t.Fatalf("future %d after rollback = %v, %v, want %v", i, f.Key(), f.Err(), dskit.ErrRolledBack)
+					}
+
+					continue
+				}
+
+				if f.Err() != nil {
+					t.Fatalf("future %d error = %v after commit", i, f.Err())
+				}
+
+				if !f.Resolved() || f.Key() == nil || f.Key().Incomplete() {
+					t.Fatalf("future %d = %v, want a complete key after commit", i, f.Key())
+				}
+
+				if _, err := r.Read(ctx, f.Key()); err != nil {
+					t.Fatalf("Read(future %d) error = %v", i, err)
+				}
+			}
+
+			if tt.fail != nil && c.Store().Len() != 0 {
+				t.Fatalf("store has %d entities after rollback, want 0", c.Store().Len())
+			}
+		})
+	}
+}
+
+func TestCreateFutureRetry(t *testing.T) {
+	ctx := context.Background()
+
+	for _, preallocate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("preallocate=%v", preallocate), func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			var opts []dskit.RepoOption[tenantNote]
+			if preallocate {
+				opts = append(opts, dskit.WithPreallocatedIDs[tenantNote]())
+			}
+
+			r := fake.NewRepo[tenantNote](c, "Note", opts...)
+
+			other, err := r.Create(ctx, nil, &tenantNote{Text: "other"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			var attempts [][]*dskit.FutureKey
+
+			_, err = c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+				if _, err := r.ReadTxn(txn, other); err != nil {
+					return err
+				}
+
+				futures, err := r.CreateMultiFutureTxn(txn, nil, []*tenantNote{{Text: "a"}, {Text: "b"}})
+				if err != nil {
+					return err
+				}
+
+				attempts = append(attempts, futures)
+
+				if len(attempts) == 1 {
+					if _, err := r.Upsert(ctx, other, &tenantNote{Text: "changed"}); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("RunInTransaction() error = %v", err)
+			}
+
+			if len(attempts) != 2 {
+				t.Fatalf("attempts = %d, want 2", len(attempts))
+			}
+
+			for i, f := range attempts[0] {
+				if f.Resolved() || f.Key() != nil || !errors.Is(f.Err(), q.ErrContention) {
+					t.Fatalf("discarded future %d = %v, %v, want failed with %v", i, f.Key(), f.Err(), q.ErrContention)
+				}
+			}
+
+			for i, f := range attempts[1] {
+				if !f.Resolved() || f.Err() != nil || f.Key() == nil || f.Key().Incomplete() {
+					t.Fatalf("committed future %d = %v, %v, want a complete key", i, f.Key(), f.Err())
+				}
+
+				if _, err := r.Read(ctx, f.Key()); err != nil {
+					t.Fatalf("Read(future %d) error = %v", i, err)
+				}
+			}
+
+			if c.Store().Len() != 3 {
+				t.Fatalf("store has %d entities, want 3", c.Store().Len())
+			}
+		})
+	}
+}
+
+func TestPreallocatedParentKeysChildren(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		write func(t *testing.T, c *fake.Client, parents, children dskit.Repo[tenantNote]) *datastore.Key
+	}{
+		{
+			name: "same transaction",
+			write: func(t *testing.T, c *fake.Client, parents, children dskit.Repo[tenantNote]) *datastore.Key {
+				var child *dskit.FutureKey
+
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+					parent, err := parents.CreateFutureTxn(txn, nil, &tenantNote{Text: "parent"})
+					if err != nil {
+						return err
+					}
+
+					child, err = children.CreateFutureTxn(txn, parent.Key(), &tenantNote{Text: "child"})
+
+					return err
+				})
+				if err != nil {
+					t.Fatalf("RunInTransaction() error = %v", err)
+				}
+
+				return child.Key()
+			},
+		},
+		{
+			name: "same batch",
+			write: func(t *testing.T, _ *fake.Client, parents, _ dskit.Repo[tenantNote]) *datastore.Key {
+				keys, err := parents.AllocateIDs(ctx, nil, 1)
+				if err != nil {
+					t.Fatalf("AllocateIDs() error = %v", err)
+				}
+
+				child := datastore.NameKey("Note", "child", keys[0])
+
+				_, err = parents.CreateMultiWithKeys(ctx, []*datastore.Key{keys[0], child}, []*tenantNote{{Text: "parent"}, {Text: "child"}})
+				if err != nil {
+					t.Fatalf("CreateMultiWithKeys() error = %v", err)
+				}
+
+				return child
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			parents := fake.NewRepo[tenantNote](c, "Note", dskit.WithPreallocatedIDs[tenantNote]())
+			children := fake.NewRepo[tenantNote](c, "Child", dskit.WithPreallocatedIDs[tenantNote]())
+
+			child := tt.write(t, c, parents, children)
+			if child == nil || child.Parent == nil || child.Parent.Incomplete() {
+				t.Fatalf("child key = %v, want a complete parent", child)
+			}
+
+			if _, err := parents.Read(ctx, child.Parent); err != nil {
+				t.Fatalf("Read(parent) error = %v", err)
+			}
+
+			if c.Store().Len() != 2 {
+				t.Fatalf("store has %d entities, want 2", c.Store().Len())
+			}
+		})
+	}
+}
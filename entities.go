@@ -0,0 +1,41 @@
+package dskit
+
+import (
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+func newEntities[E any](n int) []*E {
+	entities := make([]*E, n)
+	for i := range entities {
+		entities[i] = new(E)
+	}
+
+	return entities
+}
+
+func withHoles[E any](entities []*E, statuses []q.ReadStatus) []*E {
+	for i, s := range statuses {
+		if !s.Loaded() {
+			entities[i] = nil
+		}
+	}
+
+	return entities
+}
+
+func byKey[E any](entities []*E, statuses []q.ReadStatus) map[string]*E {
+	m := make(map[string]*E, len(entities))
+
+	for i, s := range statuses {
+		if entities[i] != nil {
+			m[keyString(s.Key)] = entities[i]
+		}
+	}
+
+	return m
+}
+
+func keyString(key *datastore.Key) string {
+	return key.Encode()
+}
@@ -0,0 +1,92 @@
+package dskit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+const slowQueryExplainTimeout = 10 * time.Second
+
+type slowQueryLog struct {
+	threshold time.Duration
+	logger    *slog.Logger
+	explain   bool
+}
+
+func (r *repo[E]) Explain(ctx context.Context, spec *q.Spec) (out *q.Explanation, err error) {
+	ctx, span := r.start(ctx, nil, "Explain")
+	defer func() { end(span, err, nil) }()
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Explain(ctx, r.client, query)
+}
+
+func (r *repo[E]) ExplainAnalyze(ctx context.Context, spec *q.Spec) (out []*E, _ *q.Explanation, err error) {
+	ctx, span := r.start(ctx, nil, "ExplainAnalyze")
+	defer func() { end(span, err, out) }()
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, explanation, err := q.ExplainAnalyze[E](ctx, r.client, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.afterLoad(ctx, out...); err != nil {
+		return nil, nil, err
+	}
+
+	return out, explanation, nil
+}
+
+func (r *repo[E]) logSlow(ctx context.Context, txn q.Transaction, operation string, spec *q.Spec, query *datastore.Query, started time.Time, err error) {
+	if r.slowQuery == nil {
+		return
+	}
+
+	elapsed := time.Since(started)
+	if elapsed < r.slowQuery.threshold {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("kind", r.kind),
+		slog.String("operation", operation),
+		slog.Duration("elapsed", elapsed),
+		slog.Any("spec", spec.Values()),
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if !r.slowQuery.explain || txn != nil || err != nil {
+		r.slowQuery.logger.LogAttrs(ctx, slog.LevelWarn, "dskit: slow query", attrs...)
+
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slowQueryExplainTimeout)
+		defer cancel()
+
+		if plan, err := q.Explain(ctx, r.client, query); err != nil {
+			attrs = append(attrs, slog.String("explain_error", err.Error()))
+		} else {
+			attrs = append(attrs, slog.Any("indexes_used", plan.IndexesUsed))
+		}
+
+		r.slowQuery.logger.LogAttrs(ctx, slog.LevelWarn, "dskit: slow query", attrs...)
+	}()
+}
@@ -0,0 +1,102 @@
+package dskit_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type records chan slog.Record
+
+func (records) Enabled(context.Context, slog.Level) bool { return true }
+func (r records) Handle(_ context.Context, rec slog.Record) error {
+	r <- rec
+
+	return nil
+}
+func (r records) WithAttrs([]slog.Attr) slog.Handler { return r }
+func (r records) WithGroup(string) slog.Handler      { return r }
+
+func attrs(rec slog.Record) map[string]slog.Value {
+	out := make(map[string]slog.Value)
+
+	rec.Attrs(func(a slog.Attr) bool {
+		out[a.Key] = a.Value
+
+		return true
+	})
+
+	return out
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		explain     bool
+		cursor      string
+		wantError   bool
+		wantIndexes bool
+		wantQueries int
+	}{
+		{name: "logs duration and spec", wantQueries: 1},
+		{name: "explain is opt in", explain: true, wantIndexes: true, wantQueries: 2},
+		{name: "failed query is not explained", explain: true, cursor: "_w", wantError: true, wantQueries: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			logged := make(records, 1)
+			logger := slog.New(logged)
+
+			opt := dskit.WithSlowQueryLog[tenantNote](0, logger)
+			if tt.explain {
+				opt = dskit.WithSlowQueryExplain[tenantNote](0, logger)
+			}
+
+			r := fake.NewRepo[tenantNote](c, "Note", opt)
+
+			_, _, err := r.Find(ctx, q.NewSpec().Filter("Text", "=", "x").Cursor(tt.cursor))
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Find() error = %v, want error %v", err, tt.wantError)
+			}
+
+			var rec slog.Record
+
+			select {
+			case rec = <-logged:
+			case <-time.After(5 * time.Second):
+				t.Fatal("slow query was not logged")
+			}
+
+			got := attrs(rec)
+
+			for _, key := range []string{"elapsed", "spec", "operation"} {
+				if _, ok := got[key]; !ok {
+					t.Fatalf("log record is missing %q: %v", key, got)
+				}
+			}
+
+			if _, ok := got["error"]; ok != tt.wantError {
+				t.Fatalf("log record error present = %v, want %v", ok, tt.wantError)
+			}
+
+			if _, ok := got["indexes_used"]; ok != tt.wantIndexes {
+				t.Fatalf("log record indexes_used present = %v, want %v", ok, tt.wantIndexes)
+			}
+
+			if n := c.Store().Calls("RunQuery"); n != tt.wantQueries {
+				t.Fatalf("RunQuery calls = %d, want %d", n, tt.wantQueries)
+			}
+		})
+	}
+}
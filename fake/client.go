@@ -0,0 +1,110 @@
+// Package fake runs the dskit client, repositories and queries against an
+// in-memory Datastore server, so tests exercise the real hooks, timestamps,
+// versioning, soft delete, batching and transaction retries without an
+// emulator.
+//
+// The server keeps a revision history per entity, detects optimistic
+// conflicts on commit and serves read-time snapshots. It does not emulate
+// composite index requirements, entity group write limits, eventual
+// consistency, GQL queries or cursor stability across writes: cursors encode
+// a position in the result set rather than the last entity returned.
+package fake
+
+import (
+	"context"
+	"net"
+
+	"cloud.google.com/go/datastore"
+	pb "cloud.google.com/go/datastore/apiv1/datastorepb"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const projectID = "fake"
+
+type Client struct {
+	client dskit.Client
+	store  *Store
+	server *grpc.Server
+}
+
+var _ dskit.Client = (*Client)(nil)
+
+func NewClient(opts ...dskit.Option) *Client {
+	c, err := newClient(NewStore(), opts)
+	if err != nil {
+		panic("fake: " + err.Error())
+	}
+
+	return c
+}
+
+func newClient(store *Store, opts []dskit.Option) (*Client, error) {
+	lis := bufconn.Listen(1 << 20)
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(store.count))
+	pb.RegisterDatastoreServer(srv, &server{store: store})
+
+	go func() { _ = srv.Serve(lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///fake",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		srv.Stop()
+
+		return nil, err
+	}
+
+	opts = append([]dskit.Option{
+		dskit.WithProjectID(projectID),
+		dskit.WithClientOptions(option.WithGRPCConn(conn)),
+	}, opts...)
+
+	client, err := dskit.NewClient(context.Background(), "", opts...)
+	if err != nil {
+		_ = conn.Close()
+		srv.Stop()
+
+		return nil, err
+	}
+
+	return &Client{client: client, store: store, server: srv}, nil
+}
+
+func (c *Client) Client() *datastore.Client {
+	return c.client.Client()
+}
+
+func (c *Client) Telemetry() *telemetry.Telemetry {
+	if t, ok := c.client.(interface{ Telemetry() *telemetry.Telemetry }); ok {
+		return t.Telemetry()
+	}
+
+	return nil
+}
+
+func (c *Client) Store() *Store {
+	return c.store
+}
+
+func (c *Client) RunInTransaction(ctx context.Context, f func(txn dskit.Transaction) error, opts ...datastore.TransactionOption) (*datastore.Commit, error) {
+	return c.client.RunInTransaction(ctx, f, opts...)
+}
+
+func (c *Client) RunReadOnly(ctx context.Context, f func(txn dskit.Transaction) error, opts ...q.ReadOption) error {
+	return c.client.RunReadOnly(ctx, f, opts...)
+}
+
+func (c *Client) Close() error {
+	err := c.client.Client().Close()
+	c.server.Stop()
+
+	return err
+}
@@ -0,0 +1,301 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	q "github.com/huysamen/dskit/query"
+)
+
+type task struct {
+	Title    string
+	Priority int
+	Tags     []string
+	Done     bool
+}
+
+func newRepo(t *testing.T, opts ...dskit.Option) (*Client, dskit.Repo[task]) {
+	t.Helper()
+
+	c := NewClient(opts...)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, NewRepo[task](c, "Task")
+}
+
+func seed(t *testing.T, r dskit.Repo[task], tasks ...task) []*datastore.Key {
+	t.Helper()
+
+	entities := make([]*task, len(tasks))
+	for i := range tasks {
+		entities[i] = &tasks[i]
+	}
+
+	keys, err := r.CreateMulti(context.Background(), nil, entities)
+	if err != nil {
+		t.Fatalf("CreateMulti() error = %v", err)
+	}
+
+	return keys
+}
+
+func TestCRUD(t *testing.T) {
+	ctx := context.Background()
+	_, r := newRepo(t)
+
+	key, err := r.Create(ctx, nil, &task{Title: "write", Priority: 1})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if key.Incomplete() {
+		t.Fatalf("Create() key %v is incomplete", key)
+	}
+
+	if err := r.Update(ctx, key, &task{Title: "write", Priority: 2}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := r.Read(ctx, key)
+	if err != nil || got.Priority != 2 {
+		t.Fatalf("Read() = %+v, %v, want priority 2", got, err)
+	}
+
+	if _, err := r.CreateWithKey(ctx, key, &task{}); err == nil {
+		t.Fatal("CreateWithKey() on an existing key succeeded")
+	}
+
+	if err := r.Update(ctx, r.KeyFromName("missing", nil), &task{}); err == nil {
+		t.Fatal("Update() of a missing entity succeeded")
+	}
+
+	if err := r.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := r.Read(ctx, key); !errors.Is(err, datastore.ErrNoSuchEntity) {
+		t.Fatalf("Read() after delete error = %v, want ErrNoSuchEntity", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	_, r := newRepo(t)
+
+	seed(t, r,
+		task{Title: "a", Priority: 3, Tags: []string{"home"}},
+		task{Title: "b", Priority: 1, Tags: []string{"work", "urgent"}},
+		task{Title: "c", Priority: 2, Tags: []string{"work"}, Done: true},
+		task{Title: "d", Priority: 5},
+	)
+
+	tests := []struct {
+		name string
+		spec *q.Spec
+		want []string
+	}{
+		{name: "equality", spec: q.NewSpec().Filter("Done", "=", true), want: []string{"c"}},
+		{name: "range and order", spec: q.NewSpec().Filter("Priority", ">=", 2).Order("Priority"), want: []string{"c", "a", "d"}},
+		{name: "descending with limit", spec: q.NewSpec().Order("-Priority").Limit(2), want: []string{"d", "a"}},
+		{name: "array membership", spec: q.NewSpec().Filter("Tags", "=", "work").Order("Title"), want: []string{"b", "c"}},
+		{name: "in", spec: q.NewSpec().Filter("Title", "in", []any{"a", "d"}).Order("Title"), want: []string{"a", "d"}},
+		{name: "not equal", spec: q.NewSpec().Filter("Priority", "!=", 1).Order("Priority"), want: []string{"c", "a", "d"}},
+		{name: "offset", spec: q.NewSpec().Order("Title").Offset(3), want: []string{"d"}},
+		{name: "type bracketing", spec: q.NewSpec().Filter("Priority", ">", "x"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := r.Find(ctx, tt.spec)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+
+			if titles := titles(got); !equal(titles, tt.want) {
+				t.Fatalf("Find() = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursors(t *testing.T) {
+	ctx := context.Background()
+	_, r := newRepo(t)
+
+	seed(t, r, task{Title: "a"}, task{Title: "b"}, task{Title: "c"}, task{Title: "d"}, task{Title: "e"})
+
+	var (
+		seen   []string
+		cursor string
+	)
+
+	for range 3 {
+		page, next, err := r.Find(ctx, q.NewSpec().Order("Title").Limit(2).Cursor(cursor))
+		if err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+
+		seen = append(seen, titles(page)...)
+
+		if next == nil {
+			break
+		}
+
+		cursor = next.String()
+	}
+
+	if want := []string{"a", "b", "c", "d", "e"}; !equal(seen, want) {
+		t.Fatalf("paged results = %v, want %v", seen, want)
+	}
+}
+
+func TestBatching(t *testing.T) {
+	ctx := context.Background()
+	_, r := newRepo(t)
+
+	tasks := make([]task, 1234)
+	for i := range tasks {
+		tasks[i] = task{Priority: i}
+	}
+
+	keys := seed(t, r, tasks...)
+
+	got, err := r.ReadMulti(ctx, keys)
+	if err != nil {
+		t.Fatalf("ReadMulti() error = %v", err)
+	}
+
+	for i, e := range got {
+		if e.Priority != i {
+			t.Fatalf("ReadMulti()[%d].Priority = %d, want %d", i, e.Priority, i)
+		}
+	}
+
+	n, err := r.Count(ctx, q.NewSpec())
+	if err != nil || n != int64(len(tasks)) {
+		t.Fatalf("Count() = %d, %v, want %d", n, err, len(tasks))
+	}
+
+	if err := r.DeleteMulti(ctx, keys); err != nil {
+		t.Fatalf("DeleteMulti() error = %v", err)
+	}
+}
+
+func TestTransactionConflictRetries(t *testing.T) {
+	ctx := context.Background()
+
+	policy := dskit.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = time.Millisecond
+
+	c, r := newRepo(t, dskit.WithRetryPolicy(policy))
+	key := seed(t, r, task{Title: "counter"})[0]
+
+	attempts := 0
+
+	_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+		attempts++
+
+		e, err := r.ReadTxn(txn, key)
+		if err != nil {
+			return err
+		}
+
+		if attempts == 1 {
+			if err := r.Update(ctx, key, &task{Title: "counter", Priority: 10}); err != nil {
+				return err
+			}
+		}
+
+		e.Priority++
+
+		return r.UpdateTxn(txn, key, e)
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction() error = %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+
+	got, err := r.Read(ctx, key)
+	if err != nil || got.Priority != 11 {
+		t.Fatalf("Read() = %+v, %v, want priority 11", got, err)
+	}
+}
+
+func TestReadOnlySnapshot(t *testing.T) {
+	ctx := context.Background()
+	c, r := newRepo(t)
+	key := seed(t, r, task{Title: "before"})[0]
+
+	readTime := time.Now()
+
+	time.Sleep(time.Millisecond)
+
+	if err := r.Update(ctx, key, &task{Title: "after"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	err := c.RunReadOnly(ctx, func(txn dskit.Transaction) error {
+		got, err := r.ReadTxn(txn, key)
+		if err != nil {
+			return err
+		}
+
+		if got.Title != "before" {
+			t.Errorf("ReadTxn() title = %q, want %q", got.Title, "before")
+		}
+
+		return r.UpdateTxn(txn, key, got)
+	}, q.WithReadTime(readTime))
+	if err == nil {
+		t.Fatal("write in a read-only transaction succeeded")
+	}
+}
+
+func TestAggregations(t *testing.T) {
+	ctx := context.Background()
+	_, r := newRepo(t)
+
+	seed(t, r, task{Priority: 1}, task{Priority: 2}, task{Priority: 6, Done: true})
+
+	n, err := r.Count(ctx, q.NewSpec().Filter("Done", "=", false))
+	if err != nil || n != 2 {
+		t.Fatalf("Count() = %d, %v, want 2", n, err)
+	}
+
+	ok, err := r.Exists(ctx, q.NewSpec().Filter("Priority", ">", 5))
+	if err != nil || !ok {
+		t.Fatalf("Exists() = %v, %v, want true", ok, err)
+	}
+}
+
+func titles(tasks []*task) []string {
+	var out []string
+
+	for _, e := range tasks {
+		out = append(out, e.Title)
+	}
+
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
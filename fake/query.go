@@ -0,0 +1,573 @@
+package fake
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"strings"
+
+	pb "cloud.google.com/go/datastore/apiv1/datastorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	namespaceKind = "__namespace__"
+	kindKind      = "__kind__"
+)
+
+func run(entities []*pb.Entity, namespace string, query *pb.Query) ([]*pb.Entity, error) {
+	if len(query.GetKind()) > 1 {
+		return nil, status.Error(codes.InvalidArgument, "a query can only have one kind")
+	}
+
+	var kind string
+
+	if len(query.GetKind()) == 1 {
+		kind = query.GetKind()[0].GetName()
+	}
+
+	candidates := candidates(entities, namespace, kind)
+	results := make([]*pb.Entity, 0, len(candidates))
+
+	for _, e := range candidates {
+		ok, err := matches(e, query.GetFilter())
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			results = append(results, e)
+		}
+	}
+
+	results = order(results, query.GetOrder())
+
+	if keysOnly(query) {
+		for i, e := range results {
+			results[i] = &pb.Entity{Key: e.GetKey()}
+		}
+
+		return results, nil
+	}
+
+	if len(query.GetProjection()) > 0 {
+		results = project(results, query.GetProjection())
+	}
+
+	return distinct(results, query.GetDistinctOn()), nil
+}
+
+func candidates(entities []*pb.Entity, namespace, kind string) []*pb.Entity {
+	switch kind {
+	case namespaceKind:
+		return namespaces(entities)
+	case kindKind:
+		return kinds(entities, namespace)
+	}
+
+	out := make([]*pb.Entity, 0, len(entities))
+
+	for _, e := range entities {
+		if e.GetKey().GetPartitionId().GetNamespaceId() != namespace {
+			continue
+		}
+
+		if kind == "" && strings.HasPrefix(kindOf(e.GetKey()), "__") {
+			continue
+		}
+
+		if kind == "" || kindOf(e.GetKey()) == kind {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+func namespaces(entities []*pb.Entity) []*pb.Entity {
+	var out []*pb.Entity
+
+	seen := make(map[string]bool)
+
+	for _, e := range entities {
+		ns := e.GetKey().GetPartitionId().GetNamespaceId()
+		if seen[ns] {
+			continue
+		}
+
+		seen[ns] = true
+
+		elem := &pb.Key_PathElement{Kind: namespaceKind, IdType: &pb.Key_PathElement_Name{Name: ns}}
+		if ns == "" {
+			elem.IdType = &pb.Key_PathElement_Id{Id: 1}
+		}
+
+		out = append(out, &pb.Entity{Key: &pb.Key{Path: []*pb.Key_PathElement{elem}}})
+	}
+
+	return order(out, nil)
+}
+
+func kinds(entities []*pb.Entity, namespace string) []*pb.Entity {
+	var out []*pb.Entity
+
+	seen := make(map[string]bool)
+
+	for _, e := range entities {
+		kind := kindOf(e.GetKey())
+		if e.GetKey().GetPartitionId().GetNamespaceId() != namespace || seen[kind] {
+			continue
+		}
+
+		seen[kind] = true
+
+		out = append(out, &pb.Entity{Key: &pb.Key{
+			PartitionId: &pb.PartitionId{NamespaceId: namespace},
+			Path:        []*pb.Key_PathElement{{Kind: kindKind, IdType: &pb.Key_PathElement_Name{Name: kind}}},
+		}})
+	}
+
+	return order(out, nil)
+}
+
+func matches(e *pb.Entity, filter *pb.Filter) (bool, error) {
+	switch f := filter.GetFilterType().(type) {
+	case nil:
+		return true, nil
+	case *pb.Filter_CompositeFilter:
+		and := f.CompositeFilter.GetOp() != pb.CompositeFilter_OR
+
+		for _, sub := range f.CompositeFilter.GetFilters() {
+			ok, err := matches(e, sub)
+			if err != nil {
+				return false, err
+			}
+
+			if ok != and {
+				return ok, nil
+			}
+		}
+
+		return and, nil
+	case *pb.Filter_PropertyFilter:
+		return matchProperty(e, f.PropertyFilter)
+	default:
+		return false, status.Errorf(codes.InvalidArgument, "unsupported filter %T", f)
+	}
+}
+
+func matchProperty(e *pb.Entity, f *pb.PropertyFilter) (bool, error) {
+	want := f.GetValue()
+
+	if f.GetOp() == pb.PropertyFilter_HAS_ANCESTOR {
+		if want.GetKeyValue() == nil {
+			return false, status.Error(codes.InvalidArgument, "ancestor filter requires a key value")
+		}
+
+		return hasAncestor(e.GetKey(), want.GetKeyValue()), nil
+	}
+
+	values := indexed(propertyValues(e, f.GetProperty().GetName()))
+
+	for _, v := range values {
+		if satisfies(v, f.GetOp(), want) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func satisfies(v *pb.Value, op pb.PropertyFilter_Operator, want *pb.Value) bool {
+	switch op {
+	case pb.PropertyFilter_IN:
+		return contains(elements(want), v)
+	case pb.PropertyFilter_NOT_IN:
+		return !contains(elements(want), v)
+	case pb.PropertyFilter_EQUAL:
+		return rank(v) == rank(want) && compareValues(v, want) == 0
+	case pb.PropertyFilter_NOT_EQUAL:
+		return rank(v) != rank(want) || compareValues(v, want) != 0
+	}
+
+	if rank(v) != rank(want) {
+		return false
+	}
+
+	c := compareValues(v, want)
+
+	switch op {
+	case pb.PropertyFilter_LESS_THAN:
+		return c < 0
+	case pb.PropertyFilter_LESS_THAN_OR_EQUAL:
+		return c <= 0
+	case pb.PropertyFilter_GREATER_THAN:
+		return c > 0
+	case pb.PropertyFilter_GREATER_THAN_OR_EQUAL:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+func contains(values []*pb.Value, v *pb.Value) bool {
+	return slices.ContainsFunc(values, func(w *pb.Value) bool {
+		return rank(v) == rank(w) && compareValues(v, w) == 0
+	})
+}
+
+type sortable struct {
+	entity *pb.Entity
+	values []*pb.Value
+}
+
+func order(entities []*pb.Entity, orders []*pb.PropertyOrder) []*pb.Entity {
+	rows := make([]sortable, 0, len(entities))
+
+	for _, e := range entities {
+		row := sortable{entity: e, values: make([]*pb.Value, len(orders))}
+		ok := true
+
+		for i, o := range orders {
+			values := indexed(propertyValues(e, o.GetProperty().GetName()))
+			if len(values) == 0 {
+				ok = false
+
+				break
+			}
+
+			pick := slices.MinFunc[[]*pb.Value]
+			if o.GetDirection() == pb.PropertyOrder_DESCENDING {
+				pick = slices.MaxFunc[[]*pb.Value]
+			}
+
+			row.values[i] = pick(values, compareValues)
+		}
+
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b sortable) int {
+		for i, o := range orders {
+			c := compareValues(a.values[i], b.values[i])
+			if o.GetDirection() == pb.PropertyOrder_DESCENDING {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+
+		return compareKeys(a.entity.GetKey(), b.entity.GetKey())
+	})
+
+	out := make([]*pb.Entity, len(rows))
+
+	for i, row := range rows {
+		out[i] = row.entity
+	}
+
+	return out
+}
+
+func keysOnly(query *pb.Query) bool {
+	projection := query.GetProjection()
+
+	return len(projection) == 1 && projection[0].GetProperty().GetName() == keyProperty
+}
+
+func project(entities []*pb.Entity, projection []*pb.Projection) []*pb.Entity {
+	var out []*pb.Entity
+
+	for _, e := range entities {
+		rows := []*pb.Entity{{Key: e.GetKey(), Properties: make(map[string]*pb.Value)}}
+
+		for _, p := range projection {
+			name := p.GetProperty().GetName()
+			if name == keyProperty {
+				continue
+			}
+
+			values := indexed(propertyValues(e, name))
+
+			var next []*pb.Entity
+
+			for _, row := range rows {
+				for _, v := range values {
+					r := proto.Clone(row).(*pb.Entity)
+					setPath(r, name, proto.Clone(v).(*pb.Value))
+					next = append(next, r)
+				}
+			}
+
+			rows = next
+		}
+
+		out = append(out, rows...)
+	}
+
+	return out
+}
+
+func setPath(e *pb.Entity, path string, v *pb.Value) {
+	if e.Properties == nil {
+		e.Properties = make(map[string]*pb.Value)
+	}
+
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		e.Properties[path] = v
+
+		return
+	}
+
+	parent := e.Properties[head].GetEntityValue()
+	if parent == nil {
+		parent = &pb.Entity{}
+		e.Properties[head] = &pb.Value{ValueType: &pb.Value_EntityValue{EntityValue: parent}}
+	}
+
+	setPath(parent, rest, v)
+}
+
+func distinct(entities []*pb.Entity, on []*pb.PropertyReference) []*pb.Entity {
+	if len(on) == 0 {
+		return entities
+	}
+
+	out := make([]*pb.Entity, 0, len(entities))
+	seen := make(map[string]bool, len(entities))
+	opts := proto.MarshalOptions{Deterministic: true}
+
+	for _, e := range entities {
+		var id strings.Builder
+
+		for _, ref := range on {
+			for _, v := range propertyValues(e, ref.GetName()) {
+				b, _ := opts.Marshal(v)
+				id.Write(b)
+			}
+
+			id.WriteByte(0)
+		}
+
+		if seen[id.String()] {
+			continue
+		}
+
+		seen[id.String()] = true
+		out = append(out, e)
+	}
+
+	return out
+}
+
+func cursor(pos int) []byte {
+	return binary.AppendUvarint(nil, uint64(pos))
+}
+
+func position(c []byte, fallback int) (int, error) {
+	if len(c) == 0 {
+		return fallback, nil
+	}
+
+	pos, n := binary.Uvarint(c)
+	if n <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid query cursor")
+	}
+
+	return int(pos), nil
+}
+
+type window struct {
+	start, skipped, end int
+	more                bool
+}
+
+func paginate(n int, query *pb.Query) (window, error) {
+	start, err := position(query.GetStartCursor(), 0)
+	if err != nil {
+		return window{}, err
+	}
+
+	end, err := position(query.GetEndCursor(), n)
+	if err != nil {
+		return window{}, err
+	}
+
+	end = min(end, n)
+	start = min(start, end)
+
+	skipped := min(int(query.GetOffset()), end-start)
+	from := start + skipped
+	to := end
+
+	if limit := query.GetLimit(); limit != nil {
+		to = min(end, from+int(limit.GetValue()))
+	}
+
+	return window{start: from, skipped: skipped, end: to, more: to < end}, nil
+}
+
+func batch(results []*pb.Entity, query *pb.Query) (*pb.QueryResultBatch, error) {
+	w, err := paginate(len(results), query)
+	if err != nil {
+		return nil, err
+	}
+
+	resultType := pb.EntityResult_FULL
+
+	switch {
+	case keysOnly(query):
+		resultType = pb.EntityResult_KEY_ONLY
+	case len(query.GetProjection()) > 0:
+		resultType = pb.EntityResult_PROJECTION
+	}
+
+	b := &pb.QueryResultBatch{
+		SkippedResults:   int32(w.skipped),
+		SkippedCursor:    cursor(w.start),
+		EntityResultType: resultType,
+		EndCursor:        cursor(w.end),
+		MoreResults:      pb.QueryResultBatch_NO_MORE_RESULTS,
+	}
+
+	if w.more {
+		b.MoreResults = pb.QueryResultBatch_MORE_RESULTS_AFTER_LIMIT
+	}
+
+	for i := w.start; i < w.end; i++ {
+		b.EntityResults = append(b.EntityResults, &pb.EntityResult{
+			Entity: clone(results[i]),
+			Cursor: cursor(i + 1),
+		})
+	}
+
+	return b, nil
+}
+
+func aggregate(results []*pb.Entity, query *pb.Query, aggregations []*pb.AggregationQuery_Aggregation) (map[string]*pb.Value, error) {
+	w, err := paginate(len(results), query)
+	if err != nil {
+		return nil, err
+	}
+
+	results = results[w.start:w.end]
+	out := make(map[string]*pb.Value, len(aggregations))
+
+	for _, a := range aggregations {
+		alias := a.GetAlias()
+		if alias == "" {
+			return nil, status.Error(codes.InvalidArgument, "aggregation alias is required")
+		}
+
+		if _, dup := out[alias]; dup {
+			return nil, status.Errorf(codes.InvalidArgument, "duplicate aggregation alias %q", alias)
+		}
+
+		switch op := a.GetOperator().(type) {
+		case *pb.AggregationQuery_Aggregation_Count_:
+			n := int64(len(results))
+			if upTo := op.Count.GetUpTo(); upTo != nil {
+				n = min(n, upTo.GetValue())
+			}
+
+			out[alias] = integerValue(n)
+		case *pb.AggregationQuery_Aggregation_Sum_:
+			out[alias] = sum(results, op.Sum.GetProperty().GetName())
+		case *pb.AggregationQuery_Aggregation_Avg_:
+			out[alias] = avg(results, op.Avg.GetProperty().GetName())
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported aggregation %T", op)
+		}
+	}
+
+	return out, nil
+}
+
+func numbers(results []*pb.Entity, property string) []*pb.Value {
+	var out []*pb.Value
+
+	for _, e := range results {
+		for _, v := range indexed(propertyValues(e, property)) {
+			if numeric(v) {
+				out = append(out, v)
+			}
+		}
+	}
+
+	return out
+}
+
+func sum(results []*pb.Entity, property string) *pb.Value {
+	var (
+		ints    int64
+		doubles float64
+		double  bool
+	)
+
+	for _, v := range numbers(results, property) {
+		if i, ok := v.GetValueType().(*pb.Value_IntegerValue); ok && !double {
+			if (i.IntegerValue > 0 && ints > math.MaxInt64-i.IntegerValue) || (i.IntegerValue < 0 && ints < math.MinInt64-i.IntegerValue) {
+				double = true
+				doubles = float64(ints) + float64(i.IntegerValue)
+
+				continue
+			}
+
+			ints += i.IntegerValue
+
+			continue
+		}
+
+		if !double {
+			double = true
+			doubles = float64(ints)
+		}
+
+		doubles += asFloat(v)
+	}
+
+	if double {
+		return doubleValue(doubles)
+	}
+
+	return integerValue(ints)
+}
+
+func avg(results []*pb.Entity, property string) *pb.Value {
+	values := numbers(results, property)
+	if len(values) == 0 {
+		return &pb.Value{ValueType: &pb.Value_NullValue{NullValue: structpb.NullValue_NULL_VALUE}}
+	}
+
+	var total float64
+
+	for _, v := range values {
+		total += asFloat(v)
+	}
+
+	return doubleValue(total / float64(len(values)))
+}
+
+func asFloat(v *pb.Value) float64 {
+	if i, ok := v.GetValueType().(*pb.Value_IntegerValue); ok {
+		return float64(i.IntegerValue)
+	}
+
+	return v.GetDoubleValue()
+}
+
+func integerValue(n int64) *pb.Value {
+	return &pb.Value{ValueType: &pb.Value_IntegerValue{IntegerValue: n}}
+}
+
+func doubleValue(f float64) *pb.Value {
+	return &pb.Value{ValueType: &pb.Value_DoubleValue{DoubleValue: f}}
+}
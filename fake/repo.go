@@ -0,0 +1,9 @@
+package fake
+
+import (
+	"github.com/huysamen/dskit"
+)
+
+func NewRepo[E any](client *Client, kind string, opts ...dskit.RepoOption[E]) dskit.Repo[E] {
+	return dskit.NewCRUDRepo(client, kind, opts...)
+}
@@ -0,0 +1,145 @@
+package fake
+
+import (
+	"context"
+	"time"
+
+	pb "cloud.google.com/go/datastore/apiv1/datastorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type server struct {
+	pb.UnimplementedDatastoreServer
+	store *Store
+}
+
+func (s *server) Lookup(_ context.Context, req *pb.LookupRequest) (*pb.LookupResponse, error) {
+	return s.store.lookup(req)
+}
+
+func (s *server) RunQuery(_ context.Context, req *pb.RunQueryRequest) (*pb.RunQueryResponse, error) {
+	query := req.GetQuery()
+	if query == nil {
+		return nil, status.Error(codes.Unimplemented, "GQL queries are not supported")
+	}
+
+	started := time.Now()
+
+	results, txnID, err := s.store.query(req.GetReadOptions(), req.GetPartitionId().GetNamespaceId(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.RunQueryResponse{Transaction: txnID, Query: query}
+
+	explain := req.GetExplainOptions()
+	if explain != nil && !explain.GetAnalyze() {
+		resp.Batch = &pb.QueryResultBatch{MoreResults: pb.QueryResultBatch_NO_MORE_RESULTS}
+		resp.ExplainMetrics = explainMetrics(query, nil)
+
+		return resp, nil
+	}
+
+	b, err := batch(results, query)
+	if err != nil {
+		return nil, err
+	}
+
+	b.ReadTime = timestamppb.Now()
+	resp.Batch = b
+
+	if explain != nil {
+		resp.ExplainMetrics = explainMetrics(query, &pb.ExecutionStats{
+			ResultsReturned:   int64(len(b.GetEntityResults())),
+			ReadOperations:    int64(len(results)),
+			ExecutionDuration: durationpb.New(time.Since(started)),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *server) RunAggregationQuery(_ context.Context, req *pb.RunAggregationQueryRequest) (*pb.RunAggregationQueryResponse, error) {
+	aq := req.GetAggregationQuery()
+	if aq == nil {
+		return nil, status.Error(codes.Unimplemented, "GQL queries are not supported")
+	}
+
+	results, txnID, err := s.store.query(req.GetReadOptions(), req.GetPartitionId().GetNamespaceId(), aq.GetNestedQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := aggregate(results, aq.GetNestedQuery(), aq.GetAggregations())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RunAggregationQueryResponse{
+		Transaction: txnID,
+		Query:       aq,
+		Batch: &pb.AggregationResultBatch{
+			AggregationResults: []*pb.AggregationResult{{AggregateProperties: values}},
+			MoreResults:        pb.QueryResultBatch_NO_MORE_RESULTS,
+			ReadTime:           timestamppb.Now(),
+		},
+	}, nil
+}
+
+func (s *server) BeginTransaction(_ context.Context, req *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error) {
+	opts := req.GetTransactionOptions()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	return &pb.BeginTransactionResponse{
+		Transaction: s.store.begin(opts.GetReadOnly() != nil, timeOf(opts.GetReadOnly().GetReadTime())),
+	}, nil
+}
+
+func (s *server) Commit(_ context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	return s.store.commit(req)
+}
+
+func (s *server) Rollback(_ context.Context, req *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	if err := s.store.rollback(req.GetTransaction()); err != nil {
+		return nil, err
+	}
+
+	return &pb.RollbackResponse{}, nil
+}
+
+func (s *server) AllocateIds(_ context.Context, req *pb.AllocateIdsRequest) (*pb.AllocateIdsResponse, error) {
+	keys, err := s.store.allocateIDs(req.GetKeys())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AllocateIdsResponse{Keys: keys}, nil
+}
+
+func (s *server) ReserveIds(_ context.Context, req *pb.ReserveIdsRequest) (*pb.ReserveIdsResponse, error) {
+	if err := s.store.reserveIDs(req.GetKeys()); err != nil {
+		return nil, err
+	}
+
+	return &pb.ReserveIdsResponse{}, nil
+}
+
+func explainMetrics(query *pb.Query, stats *pb.ExecutionStats) *pb.ExplainMetrics {
+	index := map[string]any{"query_scope": "Collection", "properties": "(__name__ ASC)"}
+	if len(query.GetKind()) == 0 {
+		index["query_scope"] = "Collection group"
+	}
+
+	summary, _ := structpb.NewStruct(index)
+
+	return &pb.ExplainMetrics{
+		PlanSummary:    &pb.PlanSummary{IndexesUsed: []*structpb.Struct{summary}},
+		ExecutionStats: stats,
+	}
+}
@@ -0,0 +1,441 @@
+package fake
+
+import (
+	"context"
+	"encoding/binary"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	pb "cloud.google.com/go/datastore/apiv1/datastorepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxCommitMutations = 500
+	maxLookupKeys      = 1000
+)
+
+type revision struct {
+	entity  *pb.Entity
+	version int64
+	time    time.Time
+}
+
+type transaction struct {
+	readOnly bool
+	readTime time.Time
+	reads    map[string]int64
+}
+
+type Store struct {
+	mu       sync.Mutex
+	entities map[string][]revision
+	txns     map[string]*transaction
+	calls    map[string]int
+	version  int64
+	nextID   int64
+	nextTxn  uint64
+	last     time.Time
+	now      func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		entities: make(map[string][]revision),
+		txns:     make(map[string]*transaction),
+		calls:    make(map[string]int),
+		now:      time.Now,
+	}
+}
+
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+
+	for _, revs := range s.entities {
+		if revs[len(revs)-1].entity != nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *Store) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[method]
+}
+
+func (s *Store) count(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	s.mu.Lock()
+	s.calls[path.Base(info.FullMethod)]++
+	s.mu.Unlock()
+
+	return handler(ctx, req)
+}
+
+func (s *Store) tick() time.Time {
+	t := s.now().UTC().Truncate(time.Microsecond)
+	if !t.After(s.last) {
+		t = s.last.Add(time.Microsecond)
+	}
+
+	s.last = t
+
+	return t
+}
+
+func (s *Store) at(id string, readTime time.Time) (*pb.Entity, int64) {
+	revs := s.entities[id]
+
+	for i := len(revs) - 1; i >= 0; i-- {
+		if readTime.IsZero() || !revs[i].time.After(readTime) {
+			return revs[i].entity, revs[i].version
+		}
+	}
+
+	return nil, 0
+}
+
+func (s *Store) snapshot(readTime time.Time) []*pb.Entity {
+	out := make([]*pb.Entity, 0, len(s.entities))
+
+	for id := range s.entities {
+		if e, _ := s.at(id, readTime); e != nil {
+			out = append(out, e)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b *pb.Entity) int {
+		return compareKeys(a.GetKey(), b.GetKey())
+	})
+
+	return out
+}
+
+func (s *Store) begin(readOnly bool, readTime time.Time) []byte {
+	if readOnly && readTime.IsZero() {
+		readTime = s.tick()
+	}
+
+	s.nextTxn++
+	id := binary.AppendUvarint(nil, s.nextTxn)
+
+	s.txns[string(id)] = &transaction{
+		readOnly: readOnly,
+		readTime: readTime,
+		reads:    make(map[string]int64),
+	}
+
+	return id
+}
+
+func (s *Store) transaction(id []byte) (*transaction, error) {
+	t, ok := s.txns[string(id)]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "transaction is invalid or has expired")
+	}
+
+	return t, nil
+}
+
+func (s *Store) readOptions(opts *pb.ReadOptions) (*transaction, []byte, time.Time, error) {
+	switch c := opts.GetConsistencyType().(type) {
+	case *pb.ReadOptions_Transaction:
+		t, err := s.transaction(c.Transaction)
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+
+		return t, nil, t.readTime, nil
+	case *pb.ReadOptions_NewTransaction:
+		id := s.begin(c.NewTransaction.GetReadOnly() != nil, timeOf(c.NewTransaction.GetReadOnly().GetReadTime()))
+		t := s.txns[string(id)]
+
+		return t, id, t.readTime, nil
+	case *pb.ReadOptions_ReadTime:
+		return nil, nil, timeOf(c.ReadTime), nil
+	default:
+		return nil, nil, time.Time{}, nil
+	}
+}
+
+func (s *Store) observe(t *transaction, id string) {
+	if t == nil || t.readOnly {
+		return
+	}
+
+	if _, ok := t.reads[id]; ok {
+		return
+	}
+
+	revs := s.entities[id]
+	if len(revs) == 0 {
+		t.reads[id] = 0
+
+		return
+	}
+
+	t.reads[id] = revs[len(revs)-1].version
+}
+
+func (s *Store) lookup(req *pb.LookupRequest) (*pb.LookupResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(req.GetKeys()) > maxLookupKeys {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot look up more than %d keys in a single call", maxLookupKeys)
+	}
+
+	t, txnID, readTime, err := s.readOptions(req.GetReadOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.LookupResponse{Transaction: txnID, ReadTime: timestamppb.New(s.tick())}
+
+	for _, k := range req.GetKeys() {
+		if incomplete(k) {
+			return nil, status.Error(codes.InvalidArgument, "key path element must not be incomplete")
+		}
+
+		id := keyID(k)
+		s.observe(t, id)
+
+		e, version := s.at(id, readTime)
+		if e == nil {
+			resp.Missing = append(resp.Missing, &pb.EntityResult{Entity: &pb.Entity{Key: k}, Version: version})
+
+			continue
+		}
+
+		resp.Found = append(resp.Found, &pb.EntityResult{Entity: clone(e), Version: version})
+	}
+
+	return resp, nil
+}
+
+func (s *Store) query(opts *pb.ReadOptions, namespace string, query *pb.Query) ([]*pb.Entity, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, txnID, readTime, err := s.readOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results, err := run(s.snapshot(readTime), namespace, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, e := range results {
+		s.observe(t, keyID(e.GetKey()))
+	}
+
+	return results, txnID, nil
+}
+
+func (s *Store) commit(req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var t *transaction
+
+	if id := req.GetTransaction(); id != nil {
+		txn, err := s.transaction(id)
+		if err != nil {
+			return nil, err
+		}
+
+		delete(s.txns, string(id))
+		t = txn
+	}
+
+	muts := req.GetMutations()
+
+	if t != nil && t.readOnly && len(muts) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot modify entities in a read-only transaction")
+	}
+
+	if len(muts) > maxCommitMutations {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot write more than %d entities in a single call", maxCommitMutations)
+	}
+
+	if t != nil {
+		for id, seen := range t.reads {
+			if _, current := s.at(id, time.Time{}); current != seen {
+				return nil, status.Error(codes.Aborted, "too much contention on these datastore entities, please try again")
+			}
+		}
+	}
+
+	if err := s.validate(muts); err != nil {
+		return nil, err
+	}
+
+	s.version++
+	now := s.tick()
+	resp := &pb.CommitResponse{CommitTime: timestamppb.New(now)}
+
+	for _, m := range muts {
+		result := &pb.MutationResult{Version: s.version, UpdateTime: timestamppb.New(now)}
+
+		if key := m.GetDelete(); key != nil {
+			id := keyID(key)
+
+			if e, _ := s.at(id, time.Time{}); e != nil {
+				s.entities[id] = append(s.entities[id], revision{version: s.version, time: now})
+			}
+
+			resp.MutationResults = append(resp.MutationResults, result)
+
+			continue
+		}
+
+		e := clone(mutationEntity(m))
+
+		if incomplete(e.GetKey()) {
+			s.allocate(e.GetKey())
+			result.Key = proto.Clone(e.GetKey()).(*pb.Key)
+		}
+
+		id := keyID(e.GetKey())
+		s.entities[id] = append(s.entities[id], revision{entity: e, version: s.version, time: now})
+		resp.MutationResults = append(resp.MutationResults, result)
+	}
+
+	resp.IndexUpdates = int32(len(muts))
+
+	return resp, nil
+}
+
+func (s *Store) validate(muts []*pb.Mutation) error {
+	seen := make(map[string]bool, len(muts))
+
+	for _, m := range muts {
+		key := m.GetDelete()
+		if key == nil {
+			key = mutationEntity(m).GetKey()
+		}
+
+		if key == nil || len(key.GetPath()) == 0 {
+			return status.Error(codes.InvalidArgument, "a mutation must have a key")
+		}
+
+		if incomplete(key) {
+			if m.GetInsert() == nil && m.GetUpsert() == nil {
+				return status.Error(codes.InvalidArgument, "key path element must not be incomplete")
+			}
+
+			continue
+		}
+
+		id := keyID(key)
+		if seen[id] {
+			return status.Error(codes.InvalidArgument, "a commit cannot contain multiple mutations affecting the same entity")
+		}
+
+		seen[id] = true
+
+		e, _ := s.at(id, time.Time{})
+
+		switch {
+		case m.GetInsert() != nil && e != nil:
+			return status.Error(codes.AlreadyExists, "entity already exists")
+		case m.GetUpdate() != nil && e == nil:
+			return status.Error(codes.NotFound, "no entity to update")
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) rollback(id []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.transaction(id); err != nil {
+		return err
+	}
+
+	delete(s.txns, string(id))
+
+	return nil
+}
+
+func (s *Store) allocate(key *pb.Key) {
+	s.nextID++
+
+	path := key.GetPath()
+	path[len(path)-1].IdType = &pb.Key_PathElement_Id{Id: s.nextID}
+}
+
+func (s *Store) allocateIDs(keys []*pb.Key) ([]*pb.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*pb.Key, len(keys))
+
+	for i, k := range keys {
+		if !incomplete(k) {
+			return nil, status.Error(codes.InvalidArgument, "cannot allocate an ID for a complete key")
+		}
+
+		out[i] = proto.Clone(k).(*pb.Key)
+		s.allocate(out[i])
+	}
+
+	return out, nil
+}
+
+func (s *Store) reserveIDs(keys []*pb.Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys {
+		if incomplete(k) {
+			return status.Error(codes.InvalidArgument, "cannot reserve an incomplete key")
+		}
+
+		path := k.GetPath()
+		s.nextID = max(s.nextID, path[len(path)-1].GetId())
+	}
+
+	return nil
+}
+
+func mutationEntity(m *pb.Mutation) *pb.Entity {
+	switch op := m.GetOperation().(type) {
+	case *pb.Mutation_Insert:
+		return op.Insert
+	case *pb.Mutation_Update:
+		return op.Update
+	case *pb.Mutation_Upsert:
+		return op.Upsert
+	default:
+		return nil
+	}
+}
+
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
+
+func clone(e *pb.Entity) *pb.Entity {
+	return proto.Clone(e).(*pb.Entity)
+}
@@ -0,0 +1,239 @@
+package fake
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"strings"
+
+	pb "cloud.google.com/go/datastore/apiv1/datastorepb"
+)
+
+const keyProperty = "__key__"
+
+func rank(v *pb.Value) int {
+	switch v.GetValueType().(type) {
+	case *pb.Value_NullValue:
+		return 0
+	case *pb.Value_IntegerValue, *pb.Value_TimestampValue:
+		return 1
+	case *pb.Value_BooleanValue:
+		return 2
+	case *pb.Value_StringValue, *pb.Value_BlobValue:
+		return 3
+	case *pb.Value_DoubleValue:
+		return 4
+	case *pb.Value_GeoPointValue:
+		return 5
+	case *pb.Value_KeyValue:
+		return 6
+	default:
+		return 7
+	}
+}
+
+func compareValues(a, b *pb.Value) int {
+	if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+		return c
+	}
+
+	switch rank(a) {
+	case 1:
+		return cmp.Compare(fixed(a), fixed(b))
+	case 2:
+		return cmp.Compare(boolean(a.GetBooleanValue()), boolean(b.GetBooleanValue()))
+	case 3:
+		return bytes.Compare(byteString(a), byteString(b))
+	case 4:
+		return cmp.Compare(a.GetDoubleValue(), b.GetDoubleValue())
+	case 5:
+		if c := cmp.Compare(a.GetGeoPointValue().GetLatitude(), b.GetGeoPointValue().GetLatitude()); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.GetGeoPointValue().GetLongitude(), b.GetGeoPointValue().GetLongitude())
+	case 6:
+		return compareKeys(a.GetKeyValue(), b.GetKeyValue())
+	default:
+		return 0
+	}
+}
+
+func fixed(v *pb.Value) int64 {
+	if ts := v.GetTimestampValue(); ts != nil {
+		return ts.AsTime().UnixMicro()
+	}
+
+	return v.GetIntegerValue()
+}
+
+func boolean(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func byteString(v *pb.Value) []byte {
+	if s, ok := v.GetValueType().(*pb.Value_StringValue); ok {
+		return []byte(s.StringValue)
+	}
+
+	return v.GetBlobValue()
+}
+
+func compareKeys(a, b *pb.Key) int {
+	if c := cmp.Compare(a.GetPartitionId().GetNamespaceId(), b.GetPartitionId().GetNamespaceId()); c != 0 {
+		return c
+	}
+
+	pathA, pathB := a.GetPath(), b.GetPath()
+
+	for i := 0; i < len(pathA) && i < len(pathB); i++ {
+		if c := comparePathElements(pathA[i], pathB[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(pathA), len(pathB))
+}
+
+func comparePathElements(a, b *pb.Key_PathElement) int {
+	if c := cmp.Compare(a.GetKind(), b.GetKind()); c != 0 {
+		return c
+	}
+
+	_, aName := a.GetIdType().(*pb.Key_PathElement_Name)
+	_, bName := b.GetIdType().(*pb.Key_PathElement_Name)
+
+	switch {
+	case aName && bName:
+		return cmp.Compare(a.GetName(), b.GetName())
+	case aName:
+		return 1
+	case bName:
+		return -1
+	default:
+		return cmp.Compare(a.GetId(), b.GetId())
+	}
+}
+
+func keyID(k *pb.Key) string {
+	var b strings.Builder
+
+	b.WriteString(k.GetPartitionId().GetNamespaceId())
+
+	for _, e := range k.GetPath() {
+		if name, ok := e.GetIdType().(*pb.Key_PathElement_Name); ok {
+			fmt.Fprintf(&b, "/%s,%q", e.GetKind(), name.Name)
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "/%s,%d", e.GetKind(), e.GetId())
+	}
+
+	return b.String()
+}
+
+func kindOf(k *pb.Key) string {
+	path := k.GetPath()
+	if len(path) == 0 {
+		return ""
+	}
+
+	return path[len(path)-1].GetKind()
+}
+
+func incomplete(k *pb.Key) bool {
+	path := k.GetPath()
+	if len(path) == 0 {
+		return true
+	}
+
+	return path[len(path)-1].GetIdType() == nil
+}
+
+func hasAncestor(k, ancestor *pb.Key) bool {
+	if k.GetPartitionId().GetNamespaceId() != ancestor.GetPartitionId().GetNamespaceId() {
+		return false
+	}
+
+	path, prefix := k.GetPath(), ancestor.GetPath()
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if comparePathElements(path[i], prefix[i]) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func numeric(v *pb.Value) bool {
+	switch v.GetValueType().(type) {
+	case *pb.Value_IntegerValue, *pb.Value_DoubleValue:
+		return true
+	default:
+		return false
+	}
+}
+
+func indexed(values []*pb.Value) []*pb.Value {
+	out := make([]*pb.Value, 0, len(values))
+
+	for _, v := range values {
+		if v.GetExcludeFromIndexes() || rank(v) == 7 {
+			continue
+		}
+
+		out = append(out, v)
+	}
+
+	return out
+}
+
+func propertyValues(e *pb.Entity, path string) []*pb.Value {
+	if path == keyProperty {
+		return []*pb.Value{{ValueType: &pb.Value_KeyValue{KeyValue: e.GetKey()}}}
+	}
+
+	return lookupPath(e.GetProperties(), path)
+}
+
+func lookupPath(props map[string]*pb.Value, path string) []*pb.Value {
+	if v, ok := props[path]; ok {
+		return elements(v)
+	}
+
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil
+	}
+
+	var out []*pb.Value
+
+	for _, v := range elements(props[head]) {
+		if ev := v.GetEntityValue(); ev != nil {
+			out = append(out, lookupPath(ev.GetProperties(), rest)...)
+		}
+	}
+
+	return out
+}
+
+func elements(v *pb.Value) []*pb.Value {
+	if v == nil {
+		return nil
+	}
+
+	if arr, ok := v.GetValueType().(*pb.Value_ArrayValue); ok {
+		return arr.ArrayValue.GetValues()
+	}
+
+	return []*pb.Value{v}
+}
@@ -0,0 +1,148 @@
+package dskit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	q "github.com/huysamen/dskit/query"
+)
+
+const tagName = "dskit"
+
+var (
+	timeType  = reflect.TypeFor[time.Time]()
+	int64Type = reflect.TypeFor[int64]()
+)
+
+type field struct {
+	name     string
+	property string
+	index    []int
+	pointer  bool
+}
+
+func taggedField[E any](tag string, types ...reflect.Type) (*field, error) {
+	t := reflect.TypeFor[E]()
+
+	if t.Kind() != reflect.Struct {
+		return nil, &q.ArgumentError{Argument: "entity", Reason: fmt.Sprintf("must be a struct to use %s:%q, got %s", tagName, tag, t)}
+	}
+
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Tag.Get(tagName) != tag {
+			continue
+		}
+
+		for _, allowed := range types {
+			if f.Type == allowed || (f.Type.Kind() == reflect.Pointer && f.Type.Elem() == allowed) {
+				return &field{
+					name:     f.Name,
+					property: propertyName(f),
+					index:    f.Index,
+					pointer:  f.Type.Kind() == reflect.Pointer,
+				}, nil
+			}
+		}
+
+		return nil, &q.ArgumentError{Argument: f.Name, Reason: fmt.Sprintf("has unsupported type %s for %s:%q", f.Type, tagName, tag)}
+	}
+
+	return nil, nil
+}
+
+func propertyName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("datastore"), ","); name != "" && name != "-" {
+		return name
+	}
+
+	return f.Name
+}
+
+func (f *field) value(entity any) (reflect.Value, bool) {
+	v, err := reflect.ValueOf(entity).Elem().FieldByIndexErr(f.index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
+
+func (f *field) time(entity any) time.Time {
+	v, ok := f.value(entity)
+	if !ok {
+		return time.Time{}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return time.Time{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v.Interface().(time.Time)
+}
+
+func (f *field) setTime(entity any, t time.Time) {
+	v, ok := f.value(entity)
+	if !ok {
+		return
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if t.IsZero() {
+			v.SetZero()
+
+			return
+		}
+
+		v.Set(reflect.ValueOf(&t))
+
+		return
+	}
+
+	v.Set(reflect.ValueOf(t))
+}
+
+func (f *field) timeValue(t time.Time) any {
+	if f.pointer && t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+func (f *field) int64(entity any) int64 {
+	v, ok := f.value(entity)
+	if !ok {
+		return 0
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0
+		}
+
+		v = v.Elem()
+	}
+
+	return v.Int()
+}
+
+func (f *field) setInt64(entity any, n int64) {
+	v, ok := f.value(entity)
+	if !ok {
+		return
+	}
+
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.ValueOf(&n))
+
+		return
+	}
+
+	v.SetInt(n)
+}
@@ -0,0 +1,125 @@
+package dskit
+
+import (
+	"errors"
+	"sync"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+var ErrRolledBack = errors.New("transaction rolled back")
+
+type FutureKey struct {
+	mu       sync.Mutex
+	pending  *datastore.PendingKey
+	key      *datastore.Key
+	resolved bool
+	err      error
+}
+
+func (f *FutureKey) Pending() *datastore.PendingKey {
+	return f.pending
+}
+
+func (f *FutureKey) Key() *datastore.Key {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.key
+}
+
+func (f *FutureKey) Resolved() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.resolved
+}
+
+func (f *FutureKey) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+func (f *FutureKey) allocate(key *datastore.Key) *FutureKey {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.key = key
+
+	return f
+}
+
+func (f *FutureKey) resolve(key *datastore.Key) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.key = key
+	f.resolved = true
+}
+
+func (f *FutureKey) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.key = nil
+	f.err = err
+}
+
+type KeyRegistry struct {
+	mu      sync.Mutex
+	futures []*FutureKey
+}
+
+func (r *KeyRegistry) Track(pending *datastore.PendingKey) *FutureKey {
+	f := &FutureKey{pending: pending}
+
+	r.mu.Lock()
+	r.futures = append(r.futures, f)
+	r.mu.Unlock()
+
+	return f
+}
+
+func (r *KeyRegistry) Resolve(resolve func(pending *datastore.PendingKey) *datastore.Key) {
+	r.mu.Lock()
+	futures := r.futures
+	r.futures = nil
+	r.mu.Unlock()
+
+	for _, f := range futures {
+		f.resolve(resolve(f.pending))
+	}
+}
+
+func (r *KeyRegistry) Fail(err error) {
+	r.mu.Lock()
+	futures := r.futures
+	r.futures = nil
+	r.mu.Unlock()
+
+	for _, f := range futures {
+		f.fail(err)
+	}
+}
+
+func TrackAll(tracker KeyTracker, pending []*datastore.PendingKey) []*FutureKey {
+	futures := make([]*FutureKey, len(pending))
+
+	for i, pk := range pending {
+		futures[i] = tracker.Track(pk)
+	}
+
+	return futures
+}
+
+func keyTracker(txn Transaction) (KeyTracker, error) {
+	tracker, ok := txn.(KeyTracker)
+	if !ok {
+		return nil, &q.ArgumentError{Argument: "transaction", Reason: "does not track pending keys"}
+	}
+
+	return tracker, nil
+}
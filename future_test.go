@@ -0,0 +1,99 @@
+package dskit
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+type plainTxn struct{}
+
+func (plainTxn) Txn() *datastore.Transaction        { return nil }
+func (plainTxn) Commit() (*datastore.Commit, error) { return nil, nil }
+func (plainTxn) Rollback() error                    { return nil }
+
+func TestKeyRegistryResolve(t *testing.T) {
+	a := &datastore.PendingKey{}
+	b := &datastore.PendingKey{}
+	keys := map[*datastore.PendingKey]*datastore.Key{
+		a: datastore.IDKey("Kind", 1, nil),
+		b: datastore.IDKey("Kind", 2, nil),
+	}
+
+	var registry KeyRegistry
+
+	futures := TrackAll(&registry, []*datastore.PendingKey{a, b})
+
+	for _, f := range futures {
+		if f.Resolved() || f.Key() != nil {
+			t.Fatalf("future resolved before commit: %v", f.Key())
+		}
+	}
+
+	registry.Resolve(func(pk *datastore.PendingKey) *datastore.Key { return keys[pk] })
+
+	for i, f := range futures {
+		if !f.Resolved() {
+			t.Fatalf("future %d not resolved", i)
+		}
+
+		if want := keys[f.Pending()]; !f.Key().Equal(want) {
+			t.Fatalf("future %d resolved to %v, want %v", i, f.Key(), want)
+		}
+	}
+
+	registry.Resolve(func(*datastore.PendingKey) *datastore.Key {
+		t.Fatal("resolved futures must not be resolved twice")
+
+		return nil
+	})
+}
+
+func TestKeyRegistryFail(t *testing.T) {
+	errCommit := errors.New("commit failed")
+
+	var registry KeyRegistry
+
+	futures := TrackAll(&registry, []*datastore.PendingKey{{}, {}})
+	futures[0].allocate(datastore.IDKey("Kind", 1, nil))
+
+	registry.Fail(errCommit)
+
+	for i, f := range futures {
+		if f.Resolved() || f.Key() != nil || !errors.Is(f.Err(), errCommit) {
+			t.Fatalf("future %d = %v, %v, want failed with %v", i, f.Key(), f.Err(), errCommit)
+		}
+	}
+
+	registry.Resolve(func(*datastore.PendingKey) *datastore.Key {
+		t.Fatal("failed futures must not be resolved")
+
+		return nil
+	})
+}
+
+func TestKeyTracker(t *testing.T) {
+	tests := []struct {
+		name    string
+		txn     Transaction
+		wantErr error
+	}{
+		{name: "tracking transaction", txn: newTransaction(t.Context(), nil, nil)},
+		{name: "plain transaction", txn: plainTxn{}, wantErr: q.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, err := keyTracker(tt.txn)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("keyTracker() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if (tracker != nil) != (tt.wantErr == nil) {
+				t.Fatalf("keyTracker() tracker = %v", tracker)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package dskit
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+)
+
+type BeforeSaver interface {
+	BeforeSave(ctx context.Context) error
+}
+
+type AfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+type Hooks[E any] struct {
+	BeforeSave   func(ctx context.Context, entity *E) error
+	AfterLoad    func(ctx context.Context, entity *E) error
+	BeforeDelete func(ctx context.Context, key *datastore.Key) error
+	AfterDelete  func(ctx context.Context, key *datastore.Key) error
+}
+
+func (r *repo[E]) beforeSave(ctx context.Context, entities ...*E) error {
+	for _, e := range entities {
+		if e == nil {
+			continue
+		}
+
+		if s, ok := any(e).(BeforeSaver); ok {
+			if err := s.BeforeSave(ctx); err != nil {
+				return err
+			}
+		}
+
+		for _, h := range r.hooks {
+			if h.BeforeSave == nil {
+				continue
+			}
+
+			if err := h.BeforeSave(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *repo[E]) afterLoad(ctx context.Context, entities ...*E) error {
+	for _, e := range entities {
+		if e == nil {
+			continue
+		}
+
+		if l, ok := any(e).(AfterLoader); ok {
+			if err := l.AfterLoad(ctx); err != nil {
+				return err
+			}
+		}
+
+		for _, h := range r.hooks {
+			if h.AfterLoad == nil {
+				continue
+			}
+
+			if err := h.AfterLoad(ctx, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *repo[E]) beforeDelete(ctx context.Context, keys ...*datastore.Key) error {
+	for _, k := range keys {
+		for _, h := range r.hooks {
+			if h.BeforeDelete == nil {
+				continue
+			}
+
+			if err := h.BeforeDelete(ctx, k); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *repo[E]) afterDelete(ctx context.Context, keys ...*datastore.Key) error {
+	for _, k := range keys {
+		for _, h := range r.hooks {
+			if h.AfterDelete == nil {
+				continue
+			}
+
+			if err := h.AfterDelete(ctx, k); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *repo[E]) loaded(ctx context.Context) func(*datastore.Key, *E) error {
+	return func(_ *datastore.Key, e *E) error {
+		return r.afterLoad(ctx, e)
+	}
+}
@@ -0,0 +1,268 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type entry struct {
+	Text   string
+	Loaded bool `datastore:"-"`
+}
+
+func TestHooksAbort(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	tests := []struct {
+		name  string
+		write func(r dskit.Repo[entry], c *fake.Client, key *datastore.Key) error
+	}{
+		{
+			name: "Create",
+			write: func(r dskit.Repo[entry], _ *fake.Client, _ *datastore.Key) error {
+				_, err := r.Create(ctx, nil, &entry{Text: "new"})
+
+				return err
+			},
+		},
+		{
+			name: "CreateMulti",
+			write: func(r dskit.Repo[entry], _ *fake.Client, _ *datastore.Key) error {
+				_, err := r.CreateMulti(ctx, nil, []*entry{{Text: "a"}, {Text: "b"}})
+
+				return err
+			},
+		},
+		{
+			name: "Update",
+			write: func(r dskit.Repo[entry], _ *fake.Client, key *datastore.Key) error {
+				return r.Update(ctx, key, &entry{Text: "changed"})
+			},
+		},
+		{
+			name: "Upsert",
+			write: func(r dskit.Repo[entry], _ *fake.Client, key *datastore.Key) error {
+				_, err := r.Upsert(ctx, key, &entry{Text: "changed"})
+
+				return err
+			},
+		},
+		{
+			name: "UpdateTxn",
+			write: func(r dskit.Repo[entry], c *fake.Client, key *datastore.Key) error {
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+					return r.UpdateTxn(txn, key, &entry{Text: "changed"})
+				})
+
+				return err
+			},
+		},
+		{
+			name: "Delete",
+			write: func(r dskit.Repo[entry], _ *fake.Client, key *datastore.Key) error {
+				return r.Delete(ctx, key)
+			},
+		},
+		{
+			name: "DeleteMulti",
+			write: func(r dskit.Repo[entry], _ *fake.Client, key *datastore.Key) error {
+				return r.DeleteMulti(ctx, []*datastore.Key{key})
+			},
+		},
+		{
+			name: "DeleteTxn",
+			write: func(r dskit.Repo[entry], c *fake.Client, key *datastore.Key) error {
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+					return r.DeleteTxn(txn, key)
+				})
+
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			key, err := fake.NewRepo[entry](c, "Entry").Create(ctx, nil, &entry{Text: "stored"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			deleted := 0
+
+			r := fake.NewRepo[entry](c, "Entry", dskit.WithHooks(dskit.Hooks[entry]{
+				BeforeSave:   func(context.Context, *entry) error { return errAbort },
+				BeforeDelete: func(context.Context, *datastore.Key) error { return errAbort },
+				AfterDelete: func(context.Context, *datastore.Key) error {
+					deleted++
+
+					return nil
+				},
+			}))
+
+			commits := c.Store().Calls("Commit")
+
+			if err := tt.write(r, c, key); !errors.Is(err, errAbort) {
+				t.Fatalf("%s() error = %v, want %v", tt.name, err, errAbort)
+			}
+
+			if got := c.Store().Calls("Commit") - commits; got != 0 {
+				t.Fatalf("%s() issued %d commits, want none", tt.name, got)
+			}
+
+			if deleted != 0 {
+				t.Fatalf("AfterDelete ran %d times, want none", deleted)
+			}
+
+			if c.Store().Len() != 1 {
+				t.Fatalf("store holds %d entities, want 1", c.Store().Len())
+			}
+
+			stored, err := r.Read(ctx, key)
+			if err != nil || stored.Text != "stored" {
+				t.Fatalf("Read() = %+v, %v, want the stored entry", stored, err)
+			}
+		})
+	}
+}
+
+func TestHooksAfterLoad(t *testing.T) {
+	ctx := context.Background()
+	errLoad := errors.New("load failed")
+
+	tests := []struct {
+		name string
+		load func(r dskit.Repo[entry], c *fake.Client, keys []*datastore.Key) ([]*entry, error)
+	}{
+		{
+			name: "Read",
+			load: func(r dskit.Repo[entry], _ *fake.Client, keys []*datastore.Key) ([]*entry, error) {
+				e, err := r.Read(ctx, keys[0])
+
+				return []*entry{e}, err
+			},
+		},
+		{
+			name: "ReadTxn",
+			load: func(r dskit.Repo[entry], c *fake.Client, keys []*datastore.Key) ([]*entry, error) {
+				var out []*entry
+
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+					e, err := r.ReadTxn(txn, keys[0])
+					out = []*entry{e}
+
+					return err
+				})
+
+				return out, err
+			},
+		},
+		{
+			name: "ReadMulti",
+			load: func(r dskit.Repo[entry], _ *fake.Client, keys []*datastore.Key) ([]*entry, error) {
+				return r.ReadMulti(ctx, keys)
+			},
+		},
+		{
+			name: "Find",
+			load: func(r dskit.Repo[entry], _ *fake.Client, _ []*datastore.Key) ([]*entry, error) {
+				out, _, err := r.Find(ctx, q.NewSpec())
+
+				return out, err
+			},
+		},
+		{
+			name: "FindTxn",
+			load: func(r dskit.Repo[entry], c *fake.Client, keys []*datastore.Key) ([]*entry, error) {
+				var out []*entry
+
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+					var err error
+
+					out, _, err = r.FindTxn(ctx, txn, q.NewSpec().Ancestor(keys[0].Parent))
+
+					return err
+				})
+
+				return out, err
+			},
+		},
+		{
+			name: "FindIter",
+			load: func(r dskit.Repo[entry], _ *fake.Client, _ []*datastore.Key) ([]*entry, error) {
+				it := r.FindIter(ctx, q.NewSpec())
+
+				var out []*entry
+
+				for e, err := range it.All() {
+					if err != nil {
+						return nil, err
+					}
+
+					out = append(out, e)
+				}
+
+				return out, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			parent := datastore.NameKey("Journal", "j", nil)
+
+			keys, err := fake.NewRepo[entry](c, "Entry").CreateMulti(ctx, parent, []*entry{{Text: "a"}, {Text: "b"}})
+			if err != nil {
+				t.Fatalf("CreateMulti() error = %v", err)
+			}
+
+			fail := false
+
+			r := fake.NewRepo[entry](c, "Entry", dskit.WithHooks(dskit.Hooks[entry]{
+				AfterLoad: func(_ context.Context, e *entry) error {
+					if fail {
+						return errLoad
+					}
+
+					e.Loaded = true
+
+					return nil
+				},
+			}))
+
+			got, err := tt.load(r, c, keys)
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+
+			if len(got) == 0 {
+				t.Fatalf("%s() returned no entities", tt.name)
+			}
+
+			for i, e := range got {
+				if e == nil || !e.Loaded {
+					t.Fatalf("%s() entity %d = %+v, want AfterLoad to have run", tt.name, i, e)
+				}
+			}
+
+			fail = true
+
+			if _, err := tt.load(r, c, keys); !errors.Is(err, errLoad) {
+				t.Fatalf("%s() error = %v, want %v", tt.name, err, errLoad)
+			}
+		})
+	}
+}
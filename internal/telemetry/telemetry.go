@@ -0,0 +1,248 @@
+package telemetry
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentation = "github.com/huysamen/dskit"
+	system          = "gcp.datastore"
+)
+
+const (
+	OperationKey     = attribute.Key("dskit.operation")
+	KindKey          = attribute.Key("dskit.kind")
+	KeyCountKey      = attribute.Key("dskit.key_count")
+	ResultCountKey   = attribute.Key("dskit.result_count")
+	CursorKey        = attribute.Key("dskit.cursor")
+	TransactionIDKey = attribute.Key("dskit.transaction.id")
+	ErrorTypeKey     = attribute.Key("error.type")
+)
+
+var (
+	global        = sync.OnceValue(func() *Telemetry { return New(nil, nil) })
+	transactionID atomic.Uint64
+)
+
+type Telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	entities metric.Int64Counter
+	errors   metric.Int64Counter
+	attrs    []attribute.KeyValue
+}
+
+func New(tp trace.TracerProvider, mp metric.MeterProvider) *Telemetry {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+
+	meter := mp.Meter(instrumentation)
+
+	duration, err := meter.Float64Histogram("dskit.operation.duration",
+		metric.WithDescription("Duration of dskit operations."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		duration = noop.Float64Histogram{}
+	}
+
+	entities, err := meter.Int64Counter("dskit.operation.entities",
+		metric.WithDescription("Number of entities read, written or returned by dskit operations."),
+		metric.WithUnit("{entity}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		entities = noop.Int64Counter{}
+	}
+
+	errors, err := meter.Int64Counter("dskit.operation.errors",
+		metric.WithDescription("Number of failed dskit operations by error class."),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		errors = noop.Int64Counter{}
+	}
+
+	return &Telemetry{
+		tracer:   tp.Tracer(instrumentation),
+		duration: duration,
+		entities: entities,
+		errors:   errors,
+	}
+}
+
+func Global() *Telemetry {
+	return global()
+}
+
+func (t *Telemetry) With(attrs ...attribute.KeyValue) *Telemetry {
+	c := *t
+	c.attrs = append(append([]attribute.KeyValue(nil), t.attrs...), attrs...)
+
+	return &c
+}
+
+func TransactionID() attribute.KeyValue {
+	return TransactionIDKey.String(strconv.FormatUint(transactionID.Add(1), 10))
+}
+
+type kindKey struct{}
+
+func WithKind(ctx context.Context, kind string) context.Context {
+	return context.WithValue(ctx, kindKey{}, kind)
+}
+
+func KindFrom(ctx context.Context) string {
+	kind, _ := ctx.Value(kindKey{}).(string)
+
+	return kind
+}
+
+func Kind(kind string) attribute.KeyValue {
+	return KindKey.String(kind)
+}
+
+func Keys(n int) attribute.KeyValue {
+	return KeyCountKey.Int(n)
+}
+
+func Cursor(present bool) attribute.KeyValue {
+	return CursorKey.Bool(present)
+}
+
+type Span struct {
+	telemetry *Telemetry
+	ctx       context.Context
+	span      trace.Span
+	start     time.Time
+	metrics   []attribute.KeyValue
+	keys      int64
+	once      sync.Once
+}
+
+func (t *Telemetry) Start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s := &Span{
+		telemetry: t,
+		start:     time.Now(),
+		keys:      -1,
+		metrics:   []attribute.KeyValue{OperationKey.String(operation)},
+	}
+
+	all := make([]attribute.KeyValue, 0, len(t.attrs)+len(attrs)+3)
+	all = append(all, attribute.String("db.system.name", system), OperationKey.String(operation))
+	all = append(all, t.attrs...)
+	all = append(all, attrs...)
+
+	kind := KindFrom(ctx)
+
+	for _, a := range all {
+		switch a.Key {
+		case KindKey:
+			kind = a.Value.AsString()
+		case KeyCountKey:
+			s.keys = a.Value.AsInt64()
+		}
+	}
+
+	if kind != "" {
+		s.metrics = append(s.metrics, Kind(kind))
+		all = append(all, Kind(kind))
+	}
+
+	s.ctx, s.span = t.tracer.Start(ctx, "dskit."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(all...),
+	)
+
+	return s.ctx, s
+}
+
+func (s *Span) SetAttributes(attrs ...attribute.KeyValue) {
+	s.span.SetAttributes(attrs...)
+}
+
+func (s *Span) AddEvent(name string, attrs ...attribute.KeyValue) {
+	s.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+func (s *Span) End(err error, class string, result any) {
+	s.once.Do(func() {
+		metrics := metric.WithAttributes(s.metrics...)
+
+		n, counted := Count(result)
+		if counted {
+			s.span.SetAttributes(ResultCountKey.Int64(n))
+		} else if s.keys >= 0 {
+			n, counted = s.keys, true
+		}
+
+		if err == nil && counted && n > 0 {
+			s.telemetry.entities.Add(s.ctx, n, metrics)
+		}
+
+		if err != nil {
+			s.span.RecordError(err)
+			s.span.SetStatus(codes.Error, err.Error())
+			s.span.SetAttributes(ErrorTypeKey.String(class))
+			s.telemetry.errors.Add(s.ctx, 1, metric.WithAttributes(append(s.metrics, ErrorTypeKey.String(class))...))
+		}
+
+		s.telemetry.duration.Record(s.ctx, time.Since(s.start).Seconds(), metrics)
+		s.span.End()
+	})
+}
+
+func Count(result any) (int64, bool) {
+	switch v := result.(type) {
+	case nil:
+		return 0, false
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	rv := reflect.ValueOf(result)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return int64(rv.Len()), true
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return 0, true
+		}
+
+		return 1, true
+	default:
+		return 0, false
+	}
+}
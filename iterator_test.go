@@ -0,0 +1,205 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type recorder struct {
+	embedded.TracerProvider
+
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type tracer struct {
+	embedded.Tracer
+
+	recorder *recorder
+}
+
+type recordedSpan struct {
+	noop.Span
+
+	name  string
+	ended bool
+	attrs map[attribute.Key]attribute.Value
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &tracer{recorder: r}
+}
+
+func (t *tracer) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+
+	s := &recordedSpan{name: name, attrs: make(map[attribute.Key]attribute.Value)}
+	t.recorder.spans = append(t.recorder.spans, s)
+
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+func (r *recorder) named(name string) *recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.spans {
+		if s.name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+type item struct {
+	N int
+}
+
+func TestIteratorSpans(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		spans []string
+		iter  func(r dskit.Repo[item]) func(yield func() bool)
+	}{
+		{
+			name:  "FindIter",
+			spans: []string{"dskit.repo.FindIter", "dskit.query.Iterate"},
+			iter: func(r dskit.Repo[item]) func(yield func() bool) {
+				return func(yield func() bool) {
+					for range r.FindIter(ctx, q.NewSpec().Order("N")).All() {
+						if !yield() {
+							break
+						}
+					}
+				}
+			},
+		},
+		{
+			name:  "ListIter",
+			spans: []string{"dskit.repo.ListIter", "dskit.repo.FindIter", "dskit.query.Iterate"},
+			iter: func(r dskit.Repo[item]) func(yield func() bool) {
+				return func(yield func() bool) {
+					for range r.ListIter(ctx, nil, "").Keyed() {
+						if !yield() {
+							break
+						}
+					}
+				}
+			},
+		},
+		{
+			name:  "FindKeysIter",
+			spans: []string{"dskit.repo.FindKeysIter", "dskit.query.IterateKeys"},
+			iter: func(r dskit.Repo[item]) func(yield func() bool) {
+				return func(yield func() bool) {
+					for range r.FindKeysIter(ctx, q.NewSpec()).Keys() {
+						if !yield() {
+							break
+						}
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, early := range []bool{false, true} {
+			name := tt.name
+			if early {
+				name += " early break"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				spans := &recorder{}
+
+				c := fake.NewClient(dskit.WithTracerProvider(spans))
+				t.Cleanup(func() { _ = c.Close() })
+
+				r := fake.NewRepo[item](c, "Item")
+
+				if _, err := r.CreateMulti(ctx, nil, []*item{{N: 1}, {N: 2}, {N: 3}}); err != nil {
+					t.Fatalf("CreateMulti() error = %v", err)
+				}
+
+				seen := 0
+
+				tt.iter(r)(func() bool {
+					seen++
+
+					for _, name := range tt.spans {
+						if s := spans.named(name); s == nil || s.ended {
+							t.Errorf("span %s ended during iteration", name)
+						}
+					}
+
+					return !early || seen < 2
+				})
+
+				want := int64(3)
+				if early {
+					want = 2
+				}
+
+				for _, name := range tt.spans {
+					s := spans.named(name)
+					if s == nil || !s.ended {
+						t.Fatalf("span %s not ended after iteration", name)
+					}
+
+					count, counted := s.attrs["dskit.result_count"]
+					if name == tt.spans[len(tt.spans)-1] {
+						if !counted || count.AsInt64() != want {
+							t.Fatalf("span %s result count = %v, want %d", name, count, want)
+						}
+
+						continue
+					}
+
+					if counted {
+						t.Fatalf("span %s also recorded result count %v", name, count)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestIteratorKeyedErr(t *testing.T) {
+	c, key := newMemos(t)
+
+	r := fake.NewRepo[memoTitle](c, "Memo", dskit.WithMismatchPolicy[memoTitle](q.FailOnMismatch, nil))
+
+	it := r.FindIter(context.Background(), q.NewSpec())
+
+	for k := range it.Keyed() {
+		t.Fatalf("Keyed() yielded %v, want the mismatch on %v to end the sequence", k, key)
+	}
+
+	if err := it.Err(); !errors.Is(err, q.ErrFieldMismatch) {
+		t.Fatalf("Err() = %v, want %v", err, q.ErrFieldMismatch)
+	}
+}
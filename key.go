@@ -0,0 +1,112 @@
+package dskit
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+type KeyOf[E any] struct {
+	*datastore.Key
+}
+
+func NewKeyOf[E any](repo Repo[E], key *datastore.Key) (KeyOf[E], error) {
+	if err := checkKind(key, repo.Kind()); err != nil {
+		return KeyOf[E]{}, err
+	}
+
+	if c, ok := repo.(KeyChecker); ok {
+		if err := c.CheckKeys(context.Background(), key); err != nil {
+			return KeyOf[E]{}, err
+		}
+	}
+
+	return KeyOf[E]{Key: key}, nil
+}
+
+func KeysOf[E any](keys []KeyOf[E]) []*datastore.Key {
+	out := make([]*datastore.Key, len(keys))
+
+	for i, k := range keys {
+		out[i] = k.Key
+	}
+
+	return out
+}
+
+func checkKind(key *datastore.Key, kind string) error {
+	if key == nil {
+		return &q.ArgumentError{Argument: "key", Reason: "cannot be nil"}
+	}
+
+	if key.Kind != kind {
+		return &q.ArgumentError{Argument: "key", Reason: fmt.Sprintf("has kind %q, expected %q", key.Kind, kind)}
+	}
+
+	return nil
+}
+
+func ParseKey(kind, encoded string) (*datastore.Key, error) {
+	key, err := datastore.DecodeKey(encoded)
+	if err != nil {
+		return nil, &q.Error{Kind: q.ErrInvalidArgument, Err: err}
+	}
+
+	if err := checkKind(key, kind); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *repo[E]) Kind() string {
+	return r.kind
+}
+
+func (r *repo[E]) KeyFromID(id int64, parent *datastore.Key) *datastore.Key {
+	return r.KeyFromIDContext(context.Background(), id, parent)
+}
+
+func (r *repo[E]) KeyFromIDContext(ctx context.Context, id int64, parent *datastore.Key) *datastore.Key {
+	k := datastore.IDKey(r.kind, id, parent)
+	k.Namespace = r.keyNamespace(ctx, parent)
+
+	return k
+}
+
+func (r *repo[E]) KeyFromName(name string, parent *datastore.Key) *datastore.Key {
+	return r.KeyFromNameContext(context.Background(), name, parent)
+}
+
+func (r *repo[E]) KeyFromNameContext(ctx context.Context, name string, parent *datastore.Key) *datastore.Key {
+	k := datastore.NameKey(r.kind, name, parent)
+	k.Namespace = r.keyNamespace(ctx, parent)
+
+	return k
+}
+
+func (r *repo[E]) IncompleteKey(parent *datastore.Key) *datastore.Key {
+	return r.IncompleteKeyContext(context.Background(), parent)
+}
+
+func (r *repo[E]) IncompleteKeyContext(ctx context.Context, parent *datastore.Key) *datastore.Key {
+	k := datastore.IncompleteKey(r.kind, parent)
+	k.Namespace = r.keyNamespace(ctx, parent)
+
+	return k
+}
+
+func (r *repo[E]) ParseKey(encoded string) (*datastore.Key, error) {
+	key, err := ParseKey(r.kind, encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.checkNamespace(context.Background(), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
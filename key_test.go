@@ -0,0 +1,126 @@
+package dskit_test
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+func TestParseKey(t *testing.T) {
+	note := datastore.NameKey("Note", "a", nil)
+	tenant := &datastore.Key{Kind: "Note", Name: "a", Namespace: "tenant-a"}
+
+	tests := []struct {
+		name    string
+		scope   string
+		encoded string
+		want    *datastore.Key
+		wantErr error
+	}{
+		{name: "valid", encoded: note.Encode(), want: note},
+		{name: "namespaced key on an unscoped repo", encoded: tenant.Encode(), want: tenant},
+		{name: "scoped repo", scope: "tenant-a", encoded: tenant.Encode(), want: tenant},
+		{name: "wrong kind", encoded: datastore.NameKey("Folder", "a", nil).Encode(), wantErr: q.ErrInvalidArgument},
+		{name: "wrong namespace", scope: "tenant-b", encoded: tenant.Encode(), wantErr: q.ErrInvalidArgument},
+		{name: "default namespace on a scoped repo", scope: "tenant-a", encoded: note.Encode(), wantErr: q.ErrInvalidArgument},
+		{name: "malformed encoding", encoded: "not-a-key", wantErr: q.ErrInvalidArgument},
+		{name: "empty encoding", wantErr: q.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			r := fake.NewRepo[tenantNote](c, "Note")
+			if tt.scope != "" {
+				r = r.WithNamespace(tt.scope)
+			}
+
+			got, err := r.ParseKey(tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseKey() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Fatalf("ParseKey() = %v, want %v", got, tt.want)
+			}
+
+			if tt.scope != "" {
+				return
+			}
+
+			if _, err := dskit.ParseKey("Note", tt.encoded); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("dskit.ParseKey() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewKeyOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   string
+		key     *datastore.Key
+		wantErr error
+	}{
+		{name: "valid", key: datastore.IDKey("Note", 1, nil)},
+		{name: "scoped repo", scope: "tenant-a", key: &datastore.Key{Kind: "Note", ID: 1, Namespace: "tenant-a"}},
+		{name: "nil key", wantErr: q.ErrInvalidArgument},
+		{name: "wrong kind", key: datastore.IDKey("Folder", 1, nil), wantErr: q.ErrInvalidArgument},
+		{name: "wrong namespace", scope: "tenant-a", key: &datastore.Key{Kind: "Note", ID: 1, Namespace: "tenant-b"}, wantErr: q.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			r := fake.NewRepo[tenantNote](c, "Note")
+			if tt.scope != "" {
+				r = r.WithNamespace(tt.scope)
+			}
+
+			got, err := dskit.NewKeyOf(r, tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewKeyOf() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && got.Key != tt.key {
+				t.Fatalf("NewKeyOf() = %v, want %v", got.Key, tt.key)
+			}
+		})
+	}
+}
+
+func TestKeyBuilders(t *testing.T) {
+	parent := &datastore.Key{Kind: "Folder", Name: "root", Namespace: "tenant-b"}
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	r := fake.NewRepo[tenantNote](c, "Note")
+
+	tests := []struct {
+		name string
+		key  *datastore.Key
+		want *datastore.Key
+	}{
+		{name: "KeyFromID", key: r.KeyFromID(1, nil), want: datastore.IDKey("Note", 1, nil)},
+		{name: "KeyFromName", key: r.KeyFromName("n", parent), want: &datastore.Key{Kind: "Note", Name: "n", Parent: parent, Namespace: "tenant-b"}},
+		{name: "IncompleteKey", key: r.IncompleteKey(parent), want: &datastore.Key{Kind: "Note", Parent: parent, Namespace: "tenant-b"}},
+		{name: "scoped KeyFromID", key: r.WithNamespace("scoped").KeyFromID(1, parent), want: &datastore.Key{Kind: "Note", ID: 1, Parent: parent, Namespace: "scoped"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.key.Equal(tt.want) {
+				t.Fatalf("%s() = %#v, want %#v", tt.name, tt.key, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package dskit
+
+import (
+	"context"
+
+	q "github.com/huysamen/dskit/query"
+)
+
+type mismatchPolicy struct {
+	policy    q.MismatchPolicy
+	handler   q.MismatchHandler
+	collected *q.Mismatches
+}
+
+func (r *repo[E]) withMismatchPolicy(ctx context.Context) context.Context {
+	if r.mismatch == nil {
+		return ctx
+	}
+
+	if _, ok := q.MismatchPolicyFromContext(ctx); ok {
+		return ctx
+	}
+
+	return q.WithMismatchCollector(ctx, r.mismatch.policy, r.mismatch.handler, r.mismatch.collected)
+}
+
+func (r *repo[E]) checkMismatchPolicy() error {
+	if r.mismatch == nil || r.mismatch.policy != q.CollectMismatches {
+		return nil
+	}
+
+	if r.mismatch.handler == nil && r.mismatch.collected == nil {
+		return &q.ArgumentError{Argument: "mismatch policy", Reason: "collects mismatches without a handler or collector"}
+	}
+
+	return nil
+}
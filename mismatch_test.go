@@ -0,0 +1,150 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type memo struct {
+	Title string
+	Body  string
+}
+
+type memoTitle struct {
+	Title string
+}
+
+type memoTyped struct {
+	Title int
+}
+
+func newMemos(t *testing.T) (*fake.Client, *datastore.Key) {
+	t.Helper()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	key, err := fake.NewRepo[memo](c, "Memo").Create(context.Background(), nil, &memo{Title: "hello", Body: "world"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	return c, key
+}
+
+func TestMismatchPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	read := func(ctx context.Context, r dskit.Repo[memoTitle]) error {
+		_, _, err := r.Find(ctx, q.NewSpec())
+
+		return err
+	}
+
+	explain := func(ctx context.Context, r dskit.Repo[memoTitle]) error {
+		_, _, err := r.ExplainAnalyze(ctx, q.NewSpec())
+
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		policy  q.MismatchPolicy
+		handler bool
+		collect bool
+		load    func(ctx context.Context, r dskit.Repo[memoTitle]) error
+		want    int
+		wantErr error
+	}{
+		{name: "collector on Find", policy: q.CollectMismatches, collect: true, load: read, want: 1},
+		{name: "handler only", policy: q.CollectMismatches, handler: true, load: read, want: 1},
+		{
+			name:    "collector on ListProjection",
+			policy:  q.CollectMismatches,
+			collect: true,
+			load: func(ctx context.Context, r dskit.Repo[memoTitle]) error {
+				generate := func() *any {
+					var dst any = new(memoTitle)
+
+					return &dst
+				}
+
+				_, _, err := r.ListProjection(ctx, nil, 0, "", generate, "Title", "Body")
+
+				return err
+			},
+			want: 1,
+		},
+		{name: "collector on ExplainAnalyze", policy: q.CollectMismatches, collect: true, load: explain, want: 1},
+		{name: "ignore", policy: q.IgnoreMismatches, collect: true, load: read},
+		{name: "ignore on ExplainAnalyze", policy: q.IgnoreMismatches, collect: true, load: explain},
+		{name: "fail", policy: q.FailOnMismatch, collect: true, load: read, want: 1, wantErr: q.ErrFieldMismatch},
+		{name: "fail on ExplainAnalyze", policy: q.FailOnMismatch, collect: true, load: explain, want: 1, wantErr: q.ErrFieldMismatch},
+		{name: "collect without handler or collector", policy: q.CollectMismatches, load: read, wantErr: q.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, key := newMemos(t)
+
+			var handled []*q.FieldMismatch
+
+			var handler q.MismatchHandler
+			if tt.handler {
+				handler = func(_ context.Context, m *q.FieldMismatch) { handled = append(handled, m) }
+			}
+
+			opts := []dskit.RepoOption[memoTitle]{dskit.WithMismatchPolicy[memoTitle](tt.policy, handler)}
+
+			collected := &q.Mismatches{}
+			if tt.collect {
+				opts = append(opts, dskit.WithMismatchCollector[memoTitle](collected))
+			}
+
+			err := tt.load(ctx, fake.NewRepo[memoTitle](c, "Memo", opts...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("load error = %v, want %v", err, tt.wantErr)
+			}
+
+			got := collected.Drain()
+			if tt.handler {
+				got = handled
+			}
+
+			if len(got) != tt.want {
+				t.Fatalf("got %d mismatches, want %d", len(got), tt.want)
+			}
+
+			for _, m := range got {
+				if !m.Key.Equal(key) || m.Field != "Body" {
+					t.Fatalf("mismatch = %v, want field %q of %v", m, "Body", key)
+				}
+			}
+
+			if collected.Len() != 0 {
+				t.Fatalf("Drain() left %d mismatches", collected.Len())
+			}
+		})
+	}
+}
+
+func TestProjectFindMismatchKey(t *testing.T) {
+	c, key := newMemos(t)
+
+	ctx, collected := q.WithMismatchPolicy(context.Background(), q.CollectMismatches, nil)
+
+	if _, _, err := dskit.ProjectFind[memo, memoTyped](ctx, fake.NewRepo[memo](c, "Memo"), q.NewSpec()); err != nil {
+		t.Fatalf("ProjectFind() error = %v", err)
+	}
+
+	got := collected.All()
+	if len(got) != 1 || !got[0].Key.Equal(key) || got[0].Field != "Title" {
+		t.Fatalf("ProjectFind() mismatches = %v, want one for field %q of %v", got, "Title", key)
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	datastore "cloud.google.com/go/datastore"
 	dskit "github.com/huysamen/dskit"
+	query "github.com/huysamen/dskit/query"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -75,3 +76,22 @@ func (mr *MockClientMockRecorder) RunInTransaction(ctx, f any, opts ...any) *gom
 	varargs := append([]any{ctx, f}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTransaction", reflect.TypeOf((*MockClient)(nil).RunInTransaction), varargs...)
 }
+
+// RunReadOnly mocks base method.
+func (m *MockClient) RunReadOnly(ctx context.Context, f func(dskit.Transaction) error, opts ...query.ReadOption) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, f}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RunReadOnly", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunReadOnly indicates an expected call of RunReadOnly.
+func (mr *MockClientMockRecorder) RunReadOnly(ctx, f any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, f}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunReadOnly", reflect.TypeOf((*MockClient)(nil).RunReadOnly), varargs...)
+}
@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	datastore "cloud.google.com/go/datastore"
 	dskit "github.com/huysamen/dskit"
@@ -43,6 +44,36 @@ func (m *MockRepo[E]) EXPECT() *MockRepoMockRecorder[E] {
 	return m.recorder
 }
 
+// AllocateIDs mocks base method.
+func (m *MockRepo[E]) AllocateIDs(ctx context.Context, parent *datastore.Key, n int) ([]*datastore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllocateIDs", ctx, parent, n)
+	ret0, _ := ret[0].([]*datastore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllocateIDs indicates an expected call of AllocateIDs.
+func (mr *MockRepoMockRecorder[E]) AllocateIDs(ctx, parent, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllocateIDs", reflect.TypeOf((*MockRepo[E])(nil).AllocateIDs), ctx, parent, n)
+}
+
+// BackfillDeletedAt mocks base method.
+func (m *MockRepo[E]) BackfillDeletedAt(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillDeletedAt", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillDeletedAt indicates an expected call of BackfillDeletedAt.
+func (mr *MockRepoMockRecorder[E]) BackfillDeletedAt(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillDeletedAt", reflect.TypeOf((*MockRepo[E])(nil).BackfillDeletedAt), ctx)
+}
+
 // Client mocks base method.
 func (m *MockRepo[E]) Client() dskit.Client {
 	m.ctrl.T.Helper()
@@ -57,6 +88,36 @@ func (mr *MockRepoMockRecorder[E]) Client() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Client", reflect.TypeOf((*MockRepo[E])(nil).Client))
 }
 
+// Count mocks base method.
+func (m *MockRepo[E]) Count(ctx context.Context, spec *query.Spec) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, spec)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockRepoMockRecorder[E]) Count(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockRepo[E])(nil).Count), ctx, spec)
+}
+
+// CountTxn mocks base method.
+func (m *MockRepo[E]) CountTxn(ctx context.Context, txn query.Transaction, spec *query.Spec) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTxn", ctx, txn, spec)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTxn indicates an expected call of CountTxn.
+func (mr *MockRepoMockRecorder[E]) CountTxn(ctx, txn, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTxn", reflect.TypeOf((*MockRepo[E])(nil).CountTxn), ctx, txn, spec)
+}
+
 // Create mocks base method.
 func (m *MockRepo[E]) Create(ctx context.Context, ancestor *datastore.Key, entity *E) (*datastore.Key, error) {
 	m.ctrl.T.Helper()
@@ -72,6 +133,21 @@ func (mr *MockRepoMockRecorder[E]) Create(ctx, ancestor, entity any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepo[E])(nil).Create), ctx, ancestor, entity)
 }
 
+// CreateFutureTxn mocks base method.
+func (m *MockRepo[E]) CreateFutureTxn(txn dskit.Transaction, ancestor *datastore.Key, entity *E) (*dskit.FutureKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFutureTxn", txn, ancestor, entity)
+	ret0, _ := ret[0].(*dskit.FutureKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFutureTxn indicates an expected call of CreateFutureTxn.
+func (mr *MockRepoMockRecorder[E]) CreateFutureTxn(txn, ancestor, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFutureTxn", reflect.TypeOf((*MockRepo[E])(nil).CreateFutureTxn), txn, ancestor, entity)
+}
+
 // CreateMulti mocks base method.
 func (m *MockRepo[E]) CreateMulti(ctx context.Context, ancestor *datastore.Key, entities []*E) ([]*datastore.Key, error) {
 	m.ctrl.T.Helper()
@@ -87,6 +163,21 @@ func (mr *MockRepoMockRecorder[E]) CreateMulti(ctx, ancestor, entities any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMulti", reflect.TypeOf((*MockRepo[E])(nil).CreateMulti), ctx, ancestor, entities)
 }
 
+// CreateMultiFutureTxn mocks base method.
+func (m *MockRepo[E]) CreateMultiFutureTxn(txn dskit.Transaction, ancestor *datastore.Key, entities []*E) ([]*dskit.FutureKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMultiFutureTxn", txn, ancestor, entities)
+	ret0, _ := ret[0].([]*dskit.FutureKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMultiFutureTxn indicates an expected call of CreateMultiFutureTxn.
+func (mr *MockRepoMockRecorder[E]) CreateMultiFutureTxn(txn, ancestor, entities any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMultiFutureTxn", reflect.TypeOf((*MockRepo[E])(nil).CreateMultiFutureTxn), txn, ancestor, entities)
+}
+
 // CreateMultiTxn mocks base method.
 func (m *MockRepo[E]) CreateMultiTxn(txn query.Transaction, ancestor *datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
 	m.ctrl.T.Helper()
@@ -233,6 +324,327 @@ func (mr *MockRepoMockRecorder[E]) DeleteTxn(txn, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTxn", reflect.TypeOf((*MockRepo[E])(nil).DeleteTxn), txn, key)
 }
 
+// Exists mocks base method.
+func (m *MockRepo[E]) Exists(ctx context.Context, spec *query.Spec) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, spec)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockRepoMockRecorder[E]) Exists(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockRepo[E])(nil).Exists), ctx, spec)
+}
+
+// ExistsTxn mocks base method.
+func (m *MockRepo[E]) ExistsTxn(ctx context.Context, txn query.Transaction, spec *query.Spec) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsTxn", ctx, txn, spec)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistsTxn indicates an expected call of ExistsTxn.
+func (mr *MockRepoMockRecorder[E]) ExistsTxn(ctx, txn, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsTxn", reflect.TypeOf((*MockRepo[E])(nil).ExistsTxn), ctx, txn, spec)
+}
+
+// Explain mocks base method.
+func (m *MockRepo[E]) Explain(ctx context.Context, spec *query.Spec) (*query.Explanation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Explain", ctx, spec)
+	ret0, _ := ret[0].(*query.Explanation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Explain indicates an expected call of Explain.
+func (mr *MockRepoMockRecorder[E]) Explain(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Explain", reflect.TypeOf((*MockRepo[E])(nil).Explain), ctx, spec)
+}
+
+// ExplainAnalyze mocks base method.
+func (m *MockRepo[E]) ExplainAnalyze(ctx context.Context, spec *query.Spec) ([]*E, *query.Explanation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExplainAnalyze", ctx, spec)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(*query.Explanation)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ExplainAnalyze indicates an expected call of ExplainAnalyze.
+func (mr *MockRepoMockRecorder[E]) ExplainAnalyze(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainAnalyze", reflect.TypeOf((*MockRepo[E])(nil).ExplainAnalyze), ctx, spec)
+}
+
+// Find mocks base method.
+func (m *MockRepo[E]) Find(ctx context.Context, spec *query.Spec) ([]*E, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, spec)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockRepoMockRecorder[E]) Find(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRepo[E])(nil).Find), ctx, spec)
+}
+
+// FindIter mocks base method.
+func (m *MockRepo[E]) FindIter(ctx context.Context, spec *query.Spec) *query.Iterator[E] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIter", ctx, spec)
+	ret0, _ := ret[0].(*query.Iterator[E])
+	return ret0
+}
+
+// FindIter indicates an expected call of FindIter.
+func (mr *MockRepoMockRecorder[E]) FindIter(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIter", reflect.TypeOf((*MockRepo[E])(nil).FindIter), ctx, spec)
+}
+
+// FindIterTxn mocks base method.
+func (m *MockRepo[E]) FindIterTxn(ctx context.Context, txn query.Transaction, spec *query.Spec) *query.Iterator[E] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIterTxn", ctx, txn, spec)
+	ret0, _ := ret[0].(*query.Iterator[E])
+	return ret0
+}
+
+// FindIterTxn indicates an expected call of FindIterTxn.
+func (mr *MockRepoMockRecorder[E]) FindIterTxn(ctx, txn, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIterTxn", reflect.TypeOf((*MockRepo[E])(nil).FindIterTxn), ctx, txn, spec)
+}
+
+// FindKeys mocks base method.
+func (m *MockRepo[E]) FindKeys(ctx context.Context, spec *query.Spec) ([]*datastore.Key, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeys", ctx, spec)
+	ret0, _ := ret[0].([]*datastore.Key)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindKeys indicates an expected call of FindKeys.
+func (mr *MockRepoMockRecorder[E]) FindKeys(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeys", reflect.TypeOf((*MockRepo[E])(nil).FindKeys), ctx, spec)
+}
+
+// FindKeysIter mocks base method.
+func (m *MockRepo[E]) FindKeysIter(ctx context.Context, spec *query.Spec) *query.Iterator[datastore.Key] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeysIter", ctx, spec)
+	ret0, _ := ret[0].(*query.Iterator[datastore.Key])
+	return ret0
+}
+
+// FindKeysIter indicates an expected call of FindKeysIter.
+func (mr *MockRepoMockRecorder[E]) FindKeysIter(ctx, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeysIter", reflect.TypeOf((*MockRepo[E])(nil).FindKeysIter), ctx, spec)
+}
+
+// FindKeysIterTxn mocks base method.
+func (m *MockRepo[E]) FindKeysIterTxn(ctx context.Context, txn query.Transaction, spec *query.Spec) *query.Iterator[datastore.Key] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeysIterTxn", ctx, txn, spec)
+	ret0, _ := ret[0].(*query.Iterator[datastore.Key])
+	return ret0
+}
+
+// FindKeysIterTxn indicates an expected call of FindKeysIterTxn.
+func (mr *MockRepoMockRecorder[E]) FindKeysIterTxn(ctx, txn, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeysIterTxn", reflect.TypeOf((*MockRepo[E])(nil).FindKeysIterTxn), ctx, txn, spec)
+}
+
+// FindKeysTxn mocks base method.
+func (m *MockRepo[E]) FindKeysTxn(ctx context.Context, txn query.Transaction, spec *query.Spec) ([]*datastore.Key, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeysTxn", ctx, txn, spec)
+	ret0, _ := ret[0].([]*datastore.Key)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindKeysTxn indicates an expected call of FindKeysTxn.
+func (mr *MockRepoMockRecorder[E]) FindKeysTxn(ctx, txn, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeysTxn", reflect.TypeOf((*MockRepo[E])(nil).FindKeysTxn), ctx, txn, spec)
+}
+
+// FindProjection mocks base method.
+func (m *MockRepo[E]) FindProjection(ctx context.Context, spec *query.Spec, fields ...string) ([]*datastore.Entity, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, spec}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindProjection", varargs...)
+	ret0, _ := ret[0].([]*datastore.Entity)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindProjection indicates an expected call of FindProjection.
+func (mr *MockRepoMockRecorder[E]) FindProjection(ctx, spec any, fields ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, spec}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProjection", reflect.TypeOf((*MockRepo[E])(nil).FindProjection), varargs...)
+}
+
+// FindProjectionTxn mocks base method.
+func (m *MockRepo[E]) FindProjectionTxn(ctx context.Context, txn query.Transaction, spec *query.Spec, fields ...string) ([]*datastore.Entity, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, txn, spec}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindProjectionTxn", varargs...)
+	ret0, _ := ret[0].([]*datastore.Entity)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindProjectionTxn indicates an expected call of FindProjectionTxn.
+func (mr *MockRepoMockRecorder[E]) FindProjectionTxn(ctx, txn, spec any, fields ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, txn, spec}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProjectionTxn", reflect.TypeOf((*MockRepo[E])(nil).FindProjectionTxn), varargs...)
+}
+
+// FindTxn mocks base method.
+func (m *MockRepo[E]) FindTxn(ctx context.Context, txn query.Transaction, spec *query.Spec) ([]*E, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTxn", ctx, txn, spec)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindTxn indicates an expected call of FindTxn.
+func (mr *MockRepoMockRecorder[E]) FindTxn(ctx, txn, spec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTxn", reflect.TypeOf((*MockRepo[E])(nil).FindTxn), ctx, txn, spec)
+}
+
+// IncompleteKey mocks base method.
+func (m *MockRepo[E]) IncompleteKey(parent *datastore.Key) *datastore.Key {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncompleteKey", parent)
+	ret0, _ := ret[0].(*datastore.Key)
+	return ret0
+}
+
+// IncompleteKey indicates an expected call of IncompleteKey.
+func (mr *MockRepoMockRecorder[E]) IncompleteKey(parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncompleteKey", reflect.TypeOf((*MockRepo[E])(nil).IncompleteKey), parent)
+}
+
+// IncompleteKeyContext mocks base method.
+func (m *MockRepo[E]) IncompleteKeyContext(ctx context.Context, parent *datastore.Key) *datastore.Key {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncompleteKeyContext", ctx, parent)
+	ret0, _ := ret[0].(*datastore.Key)
+	return ret0
+}
+
+// IncompleteKeyContext indicates an expected call of IncompleteKeyContext.
+func (mr *MockRepoMockRecorder[E]) IncompleteKeyContext(ctx, parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncompleteKeyContext", reflect.TypeOf((*MockRepo[E])(nil).IncompleteKeyContext), ctx, parent)
+}
+
+// KeyFromID mocks base method.
+func (m *MockRepo[E]) KeyFromID(id int64, parent *datastore.Key) *datastore.Key {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyFromID", id, parent)
+	ret0, _ := ret[0].(*datastore.Key)
+	return ret0
+}
+
+// KeyFromID indicates an expected call of KeyFromID.
+func (mr *MockRepoMockRecorder[E]) KeyFromID(id, parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyFromID", reflect.TypeOf((*MockRepo[E])(nil).KeyFromID), id, parent)
+}
+
+// KeyFromIDContext mocks base method.
+func (m *MockRepo[E]) KeyFromIDContext(ctx context.Context, id int64, parent *datastore.Key) *datastore.Key {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyFromIDContext", ctx, id, parent)
+	ret0, _ := ret[0].(*datastore.Key)
+	return ret0
+}
+
+// KeyFromIDContext indicates an expected call of KeyFromIDContext.
+func (mr *MockRepoMockRecorder[E]) KeyFromIDContext(ctx, id, parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyFromIDContext", reflect.TypeOf((*MockRepo[E])(nil).KeyFromIDContext), ctx, id, parent)
+}
+
+// KeyFromName mocks base method.
+func (m *MockRepo[E]) KeyFromName(name string, parent *datastore.Key) *datastore.Key {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyFromName", name, parent)
+	ret0, _ := ret[0].(*datastore.Key)
+	return ret0
+}
+
+// KeyFromName indicates an expected call of KeyFromName.
+func (mr *MockRepoMockRecorder[E]) KeyFromName(name, parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyFromName", reflect.TypeOf((*MockRepo[E])(nil).KeyFromName), name, parent)
+}
+
+// KeyFromNameContext mocks base method.
+func (m *MockRepo[E]) KeyFromNameContext(ctx context.Context, name string, parent *datastore.Key) *datastore.Key {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyFromNameContext", ctx, name, parent)
+	ret0, _ := ret[0].(*datastore.Key)
+	return ret0
+}
+
+// KeyFromNameContext indicates an expected call of KeyFromNameContext.
+func (mr *MockRepoMockRecorder[E]) KeyFromNameContext(ctx, name, parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyFromNameContext", reflect.TypeOf((*MockRepo[E])(nil).KeyFromNameContext), ctx, name, parent)
+}
+
+// Kind mocks base method.
+func (m *MockRepo[E]) Kind() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Kind")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Kind indicates an expected call of Kind.
+func (mr *MockRepoMockRecorder[E]) Kind() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Kind", reflect.TypeOf((*MockRepo[E])(nil).Kind))
+}
+
 // List mocks base method.
 func (m *MockRepo[E]) List(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error) {
 	m.ctrl.T.Helper()
@@ -349,6 +761,66 @@ func (mr *MockRepoMockRecorder[E]) ListAllTxn(ctx, txn, ancestor any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllTxn", reflect.TypeOf((*MockRepo[E])(nil).ListAllTxn), ctx, txn, ancestor)
 }
 
+// ListDeleted mocks base method.
+func (m *MockRepo[E]) ListDeleted(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, ancestor, limit, cursor)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockRepoMockRecorder[E]) ListDeleted(ctx, ancestor, limit, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockRepo[E])(nil).ListDeleted), ctx, ancestor, limit, cursor)
+}
+
+// ListDeletedTxn mocks base method.
+func (m *MockRepo[E]) ListDeletedTxn(ctx context.Context, txn query.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletedTxn", ctx, txn, ancestor, limit, cursor)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeletedTxn indicates an expected call of ListDeletedTxn.
+func (mr *MockRepoMockRecorder[E]) ListDeletedTxn(ctx, txn, ancestor, limit, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedTxn", reflect.TypeOf((*MockRepo[E])(nil).ListDeletedTxn), ctx, txn, ancestor, limit, cursor)
+}
+
+// ListIter mocks base method.
+func (m *MockRepo[E]) ListIter(ctx context.Context, ancestor *datastore.Key, cursor string) *query.Iterator[E] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIter", ctx, ancestor, cursor)
+	ret0, _ := ret[0].(*query.Iterator[E])
+	return ret0
+}
+
+// ListIter indicates an expected call of ListIter.
+func (mr *MockRepoMockRecorder[E]) ListIter(ctx, ancestor, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIter", reflect.TypeOf((*MockRepo[E])(nil).ListIter), ctx, ancestor, cursor)
+}
+
+// ListIterTxn mocks base method.
+func (m *MockRepo[E]) ListIterTxn(ctx context.Context, txn query.Transaction, ancestor *datastore.Key, cursor string) *query.Iterator[E] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIterTxn", ctx, txn, ancestor, cursor)
+	ret0, _ := ret[0].(*query.Iterator[E])
+	return ret0
+}
+
+// ListIterTxn indicates an expected call of ListIterTxn.
+func (mr *MockRepoMockRecorder[E]) ListIterTxn(ctx, txn, ancestor, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIterTxn", reflect.TypeOf((*MockRepo[E])(nil).ListIterTxn), ctx, txn, ancestor, cursor)
+}
+
 // ListKeys mocks base method.
 func (m *MockRepo[E]) ListKeys(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*datastore.Key, *datastore.Cursor, error) {
 	m.ctrl.T.Helper()
@@ -365,6 +837,34 @@ func (mr *MockRepoMockRecorder[E]) ListKeys(ctx, ancestor, limit, cursor any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockRepo[E])(nil).ListKeys), ctx, ancestor, limit, cursor)
 }
 
+// ListKeysIter mocks base method.
+func (m *MockRepo[E]) ListKeysIter(ctx context.Context, ancestor *datastore.Key, cursor string) *query.Iterator[datastore.Key] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeysIter", ctx, ancestor, cursor)
+	ret0, _ := ret[0].(*query.Iterator[datastore.Key])
+	return ret0
+}
+
+// ListKeysIter indicates an expected call of ListKeysIter.
+func (mr *MockRepoMockRecorder[E]) ListKeysIter(ctx, ancestor, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeysIter", reflect.TypeOf((*MockRepo[E])(nil).ListKeysIter), ctx, ancestor, cursor)
+}
+
+// ListKeysIterTxn mocks base method.
+func (m *MockRepo[E]) ListKeysIterTxn(ctx context.Context, txn query.Transaction, ancestor *datastore.Key, cursor string) *query.Iterator[datastore.Key] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeysIterTxn", ctx, txn, ancestor, cursor)
+	ret0, _ := ret[0].(*query.Iterator[datastore.Key])
+	return ret0
+}
+
+// ListKeysIterTxn indicates an expected call of ListKeysIterTxn.
+func (mr *MockRepoMockRecorder[E]) ListKeysIterTxn(ctx, txn, ancestor, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeysIterTxn", reflect.TypeOf((*MockRepo[E])(nil).ListKeysIterTxn), ctx, txn, ancestor, cursor)
+}
+
 // ListKeysTxn mocks base method.
 func (m *MockRepo[E]) ListKeysTxn(ctx context.Context, txn query.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*datastore.Key, *datastore.Cursor, error) {
 	m.ctrl.T.Helper()
@@ -456,7 +956,7 @@ func (mr *MockRepoMockRecorder[E]) ListPageTxn(ctx, txn, ancestor, limit, offset
 }
 
 // ListProjection mocks base method.
-func (m *MockRepo[E]) ListProjection(ctx context.Context, ancestor *datastore.Key, limit int, cursor string, generate query.Generator[any], fields ...string) ([]*any, error) {
+func (m *MockRepo[E]) ListProjection(ctx context.Context, ancestor *datastore.Key, limit int, cursor string, generate query.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error) {
 	m.ctrl.T.Helper()
 	varargs := []any{ctx, ancestor, limit, cursor, generate}
 	for _, a := range fields {
@@ -464,8 +964,9 @@ func (m *MockRepo[E]) ListProjection(ctx context.Context, ancestor *datastore.Ke
 	}
 	ret := m.ctrl.Call(m, "ListProjection", varargs...)
 	ret0, _ := ret[0].([]*any)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // ListProjection indicates an expected call of ListProjection.
@@ -476,22 +977,23 @@ func (mr *MockRepoMockRecorder[E]) ListProjection(ctx, ancestor, limit, cursor,
 }
 
 // ListProjectionTxn mocks base method.
-func (m *MockRepo[E]) ListProjectionTxn(ctx context.Context, txn query.Transaction, ancestor *datastore.Key, generate query.Generator[any], fields ...string) ([]*any, error) {
+func (m *MockRepo[E]) ListProjectionTxn(ctx context.Context, txn query.Transaction, ancestor *datastore.Key, limit int, cursor string, generate query.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, txn, ancestor, generate}
+	varargs := []any{ctx, txn, ancestor, limit, cursor, generate}
 	for _, a := range fields {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "ListProjectionTxn", varargs...)
 	ret0, _ := ret[0].([]*any)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*datastore.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // ListProjectionTxn indicates an expected call of ListProjectionTxn.
-func (mr *MockRepoMockRecorder[E]) ListProjectionTxn(ctx, txn, ancestor, generate any, fields ...any) *gomock.Call {
+func (mr *MockRepoMockRecorder[E]) ListProjectionTxn(ctx, txn, ancestor, limit, cursor, generate any, fields ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, txn, ancestor, generate}, fields...)
+	varargs := append([]any{ctx, txn, ancestor, limit, cursor, generate}, fields...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectionTxn", reflect.TypeOf((*MockRepo[E])(nil).ListProjectionTxn), varargs...)
 }
 
@@ -511,6 +1013,126 @@ func (mr *MockRepoMockRecorder[E]) ListTxn(ctx, txn, ancestor, limit, cursor any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTxn", reflect.TypeOf((*MockRepo[E])(nil).ListTxn), ctx, txn, ancestor, limit, cursor)
 }
 
+// ParseKey mocks base method.
+func (m *MockRepo[E]) ParseKey(encoded string) (*datastore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseKey", encoded)
+	ret0, _ := ret[0].(*datastore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseKey indicates an expected call of ParseKey.
+func (mr *MockRepoMockRecorder[E]) ParseKey(encoded any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseKey", reflect.TypeOf((*MockRepo[E])(nil).ParseKey), encoded)
+}
+
+// Patch mocks base method.
+func (m *MockRepo[E]) Patch(ctx context.Context, key *datastore.Key, patch func(*E) error) (*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, key, patch)
+	ret0, _ := ret[0].(*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockRepoMockRecorder[E]) Patch(ctx, key, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockRepo[E])(nil).Patch), ctx, key, patch)
+}
+
+// PatchFields mocks base method.
+func (m *MockRepo[E]) PatchFields(ctx context.Context, key *datastore.Key, fields map[string]any) (*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchFields", ctx, key, fields)
+	ret0, _ := ret[0].(*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchFields indicates an expected call of PatchFields.
+func (mr *MockRepoMockRecorder[E]) PatchFields(ctx, key, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchFields", reflect.TypeOf((*MockRepo[E])(nil).PatchFields), ctx, key, fields)
+}
+
+// PatchFieldsTxn mocks base method.
+func (m *MockRepo[E]) PatchFieldsTxn(txn query.Transaction, key *datastore.Key, fields map[string]any) (*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchFieldsTxn", txn, key, fields)
+	ret0, _ := ret[0].(*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchFieldsTxn indicates an expected call of PatchFieldsTxn.
+func (mr *MockRepoMockRecorder[E]) PatchFieldsTxn(txn, key, fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchFieldsTxn", reflect.TypeOf((*MockRepo[E])(nil).PatchFieldsTxn), txn, key, fields)
+}
+
+// PatchMulti mocks base method.
+func (m *MockRepo[E]) PatchMulti(ctx context.Context, keys []*datastore.Key, patch func(*E) error) ([]*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchMulti", ctx, keys, patch)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchMulti indicates an expected call of PatchMulti.
+func (mr *MockRepoMockRecorder[E]) PatchMulti(ctx, keys, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchMulti", reflect.TypeOf((*MockRepo[E])(nil).PatchMulti), ctx, keys, patch)
+}
+
+// PatchMultiTxn mocks base method.
+func (m *MockRepo[E]) PatchMultiTxn(txn query.Transaction, keys []*datastore.Key, patch func(*E) error) ([]*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchMultiTxn", txn, keys, patch)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchMultiTxn indicates an expected call of PatchMultiTxn.
+func (mr *MockRepoMockRecorder[E]) PatchMultiTxn(txn, keys, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchMultiTxn", reflect.TypeOf((*MockRepo[E])(nil).PatchMultiTxn), txn, keys, patch)
+}
+
+// PatchTxn mocks base method.
+func (m *MockRepo[E]) PatchTxn(txn query.Transaction, key *datastore.Key, patch func(*E) error) (*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchTxn", txn, key, patch)
+	ret0, _ := ret[0].(*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchTxn indicates an expected call of PatchTxn.
+func (mr *MockRepoMockRecorder[E]) PatchTxn(txn, key, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTxn", reflect.TypeOf((*MockRepo[E])(nil).PatchTxn), txn, key, patch)
+}
+
+// Purge mocks base method.
+func (m *MockRepo[E]) Purge(ctx context.Context, olderThan time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, olderThan)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockRepoMockRecorder[E]) Purge(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepo[E])(nil).Purge), ctx, olderThan)
+}
+
 // Read mocks base method.
 func (m *MockRepo[E]) Read(ctx context.Context, key *datastore.Key) (*E, error) {
 	m.ctrl.T.Helper()
@@ -541,6 +1163,68 @@ func (mr *MockRepoMockRecorder[E]) ReadMulti(ctx, keys any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMulti", reflect.TypeOf((*MockRepo[E])(nil).ReadMulti), ctx, keys)
 }
 
+// ReadMultiMap mocks base method.
+func (m *MockRepo[E]) ReadMultiMap(ctx context.Context, keys []*datastore.Key) (map[string]*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMultiMap", ctx, keys)
+	ret0, _ := ret[0].(map[string]*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMultiMap indicates an expected call of ReadMultiMap.
+func (mr *MockRepoMockRecorder[E]) ReadMultiMap(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMultiMap", reflect.TypeOf((*MockRepo[E])(nil).ReadMultiMap), ctx, keys)
+}
+
+// ReadMultiMapTxn mocks base method.
+func (m *MockRepo[E]) ReadMultiMapTxn(txn query.Transaction, keys []*datastore.Key) (map[string]*E, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMultiMapTxn", txn, keys)
+	ret0, _ := ret[0].(map[string]*E)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMultiMapTxn indicates an expected call of ReadMultiMapTxn.
+func (mr *MockRepoMockRecorder[E]) ReadMultiMapTxn(txn, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMultiMapTxn", reflect.TypeOf((*MockRepo[E])(nil).ReadMultiMapTxn), txn, keys)
+}
+
+// ReadMultiPartial mocks base method.
+func (m *MockRepo[E]) ReadMultiPartial(ctx context.Context, keys []*datastore.Key) ([]*E, []query.ReadStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMultiPartial", ctx, keys)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].([]query.ReadStatus)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadMultiPartial indicates an expected call of ReadMultiPartial.
+func (mr *MockRepoMockRecorder[E]) ReadMultiPartial(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMultiPartial", reflect.TypeOf((*MockRepo[E])(nil).ReadMultiPartial), ctx, keys)
+}
+
+// ReadMultiPartialTxn mocks base method.
+func (m *MockRepo[E]) ReadMultiPartialTxn(txn query.Transaction, keys []*datastore.Key) ([]*E, []query.ReadStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMultiPartialTxn", txn, keys)
+	ret0, _ := ret[0].([]*E)
+	ret1, _ := ret[1].([]query.ReadStatus)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadMultiPartialTxn indicates an expected call of ReadMultiPartialTxn.
+func (mr *MockRepoMockRecorder[E]) ReadMultiPartialTxn(txn, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMultiPartialTxn", reflect.TypeOf((*MockRepo[E])(nil).ReadMultiPartialTxn), txn, keys)
+}
+
 // ReadMultiTxn mocks base method.
 func (m *MockRepo[E]) ReadMultiTxn(txn query.Transaction, keys []*datastore.Key) ([]*E, error) {
 	m.ctrl.T.Helper()
@@ -571,6 +1255,48 @@ func (mr *MockRepoMockRecorder[E]) ReadTxn(txn, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTxn", reflect.TypeOf((*MockRepo[E])(nil).ReadTxn), txn, key)
 }
 
+// ReserveIDs mocks base method.
+func (m *MockRepo[E]) ReserveIDs(ctx context.Context, keys []*datastore.Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIDs", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveIDs indicates an expected call of ReserveIDs.
+func (mr *MockRepoMockRecorder[E]) ReserveIDs(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIDs", reflect.TypeOf((*MockRepo[E])(nil).ReserveIDs), ctx, keys)
+}
+
+// Restore mocks base method.
+func (m *MockRepo[E]) Restore(ctx context.Context, key *datastore.Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepoMockRecorder[E]) Restore(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepo[E])(nil).Restore), ctx, key)
+}
+
+// RestoreTxn mocks base method.
+func (m *MockRepo[E]) RestoreTxn(txn query.Transaction, key *datastore.Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTxn", txn, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTxn indicates an expected call of RestoreTxn.
+func (mr *MockRepoMockRecorder[E]) RestoreTxn(txn, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTxn", reflect.TypeOf((*MockRepo[E])(nil).RestoreTxn), txn, key)
+}
+
 // Update mocks base method.
 func (m *MockRepo[E]) Update(ctx context.Context, key *datastore.Key, entity *E) error {
 	m.ctrl.T.Helper()
@@ -626,3 +1352,77 @@ func (mr *MockRepoMockRecorder[E]) UpdateTxn(txn, key, entity any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTxn", reflect.TypeOf((*MockRepo[E])(nil).UpdateTxn), txn, key, entity)
 }
+
+// Upsert mocks base method.
+func (m *MockRepo[E]) Upsert(ctx context.Context, key *datastore.Key, entity *E) (*datastore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, key, entity)
+	ret0, _ := ret[0].(*datastore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockRepoMockRecorder[E]) Upsert(ctx, key, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockRepo[E])(nil).Upsert), ctx, key, entity)
+}
+
+// UpsertMulti mocks base method.
+func (m *MockRepo[E]) UpsertMulti(ctx context.Context, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertMulti", ctx, keys, entities)
+	ret0, _ := ret[0].([]*datastore.Key)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertMulti indicates an expected call of UpsertMulti.
+func (mr *MockRepoMockRecorder[E]) UpsertMulti(ctx, keys, entities any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertMulti", reflect.TypeOf((*MockRepo[E])(nil).UpsertMulti), ctx, keys, entities)
+}
+
+// UpsertMultiTxn mocks base method.
+func (m *MockRepo[E]) UpsertMultiTxn(txn query.Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertMultiTxn", txn, keys, entities)
+	ret0, _ := ret[0].([]*datastore.PendingKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertMultiTxn indicates an expected call of UpsertMultiTxn.
+func (mr *MockRepoMockRecorder[E]) UpsertMultiTxn(txn, keys, entities any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertMultiTxn", reflect.TypeOf((*MockRepo[E])(nil).UpsertMultiTxn), txn, keys, entities)
+}
+
+// UpsertTxn mocks base method.
+func (m *MockRepo[E]) UpsertTxn(txn query.Transaction, key *datastore.Key, entity *E) (*datastore.PendingKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTxn", txn, key, entity)
+	ret0, _ := ret[0].(*datastore.PendingKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTxn indicates an expected call of UpsertTxn.
+func (mr *MockRepoMockRecorder[E]) UpsertTxn(txn, key, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTxn", reflect.TypeOf((*MockRepo[E])(nil).UpsertTxn), txn, key, entity)
+}
+
+// WithNamespace mocks base method.
+func (m *MockRepo[E]) WithNamespace(namespace string) dskit.Repo[E] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithNamespace", namespace)
+	ret0, _ := ret[0].(dskit.Repo[E])
+	return ret0
+}
+
+// WithNamespace indicates an expected call of WithNamespace.
+func (mr *MockRepoMockRecorder[E]) WithNamespace(namespace any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithNamespace", reflect.TypeOf((*MockRepo[E])(nil).WithNamespace), namespace)
+}
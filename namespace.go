@@ -0,0 +1,126 @@
+package dskit
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+type namespaceKey struct{}
+
+func ContextWithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+func NamespaceFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	ns, ok := ctx.Value(namespaceKey{}).(string)
+
+	return ns, ok
+}
+
+func (r *repo[E]) WithNamespace(namespace string) Repo[E] {
+	c := *r
+	c.namespace = namespace
+	c.scoped = true
+
+	return &c
+}
+
+func (r *repo[E]) resolveNamespace(ctx context.Context) (string, bool, error) {
+	ns, ok := NamespaceFromContext(ctx)
+
+	if !r.scoped {
+		return ns, ok, nil
+	}
+
+	if ok && ns != r.namespace {
+		return "", false, &q.ArgumentError{Argument: "ctx", Reason: fmt.Sprintf("namespace %q conflicts with repo namespace %q", ns, r.namespace)}
+	}
+
+	return r.namespace, true, nil
+}
+
+type KeyChecker interface {
+	CheckKeys(ctx context.Context, keys ...*datastore.Key) error
+}
+
+func (r *repo[E]) CheckKeys(ctx context.Context, keys ...*datastore.Key) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.checkNamespace(ctx, keys...)
+}
+
+func (r *repo[E]) checkNamespace(ctx context.Context, keys ...*datastore.Key) error {
+	ns, ok, err := r.resolveNamespace(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	for _, k := range keys {
+		if k != nil && k.Namespace != ns {
+			return &q.ArgumentError{Argument: "key", Reason: fmt.Sprintf("%v is in namespace %q, expected %q", k, k.Namespace, ns)}
+		}
+	}
+
+	return nil
+}
+
+func (r *repo[E]) newKeys(ctx context.Context, ancestor *datastore.Key, n int) ([]*datastore.Key, error) {
+	if err := r.checkNamespace(ctx, ancestor); err != nil {
+		return nil, err
+	}
+
+	ns := r.keyNamespace(ctx, ancestor)
+
+	keys := make([]*datastore.Key, n)
+
+	for i := range keys {
+		keys[i] = datastore.IncompleteKey(r.kind, ancestor)
+		keys[i].Namespace = ns
+	}
+
+	return keys, nil
+}
+
+func (r *repo[E]) keyNamespace(ctx context.Context, parent *datastore.Key) string {
+	if r.scoped {
+		return r.namespace
+	}
+
+	if ns, ok := NamespaceFromContext(ctx); ok {
+		return ns
+	}
+
+	if parent != nil {
+		return parent.Namespace
+	}
+
+	return r.namespace
+}
+
+func (r *repo[E]) scopeSpec(ctx context.Context, spec *q.Spec) (*q.Spec, error) {
+	ns, ok, err := r.resolveNamespace(ctx)
+	if err != nil || !ok {
+		return spec, err
+	}
+
+	v := spec.Values()
+
+	if v.Namespace != "" && v.Namespace != ns {
+		return nil, &q.ArgumentError{Argument: "spec", Reason: fmt.Sprintf("namespace %q conflicts with %q", v.Namespace, ns)}
+	}
+
+	if err := r.checkNamespace(ctx, v.Ancestor); err != nil {
+		return nil, err
+	}
+
+	return spec.Namespace(ns), nil
+}
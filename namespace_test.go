@@ -0,0 +1,80 @@
+package dskit_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+)
+
+type tenantNote struct {
+	Text string
+}
+
+func TestKeyBuildersNamespace(t *testing.T) {
+	background := context.Background()
+	tenant := dskit.ContextWithNamespace(background, "tenant-a")
+	parent := &datastore.Key{Kind: "Folder", Name: "root", Namespace: "tenant-b"}
+
+	tests := []struct {
+		name   string
+		scope  string
+		ctx    context.Context
+		parent *datastore.Key
+		want   string
+	}{
+		{name: "no namespace", ctx: background},
+		{name: "context namespace", ctx: tenant, want: "tenant-a"},
+		{name: "parent namespace", ctx: background, parent: parent, want: "tenant-b"},
+		{name: "context wins over parent", ctx: tenant, parent: parent, want: "tenant-a"},
+		{name: "scoped repo", scope: "scoped", ctx: background, parent: parent, want: "scoped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			r := fake.NewRepo[tenantNote](c, "Note")
+			if tt.scope != "" {
+				r = r.WithNamespace(tt.scope)
+			}
+
+			keys := map[string]*datastore.Key{
+				"KeyFromID":     r.KeyFromIDContext(tt.ctx, 1, tt.parent),
+				"KeyFromName":   r.KeyFromNameContext(tt.ctx, "n", tt.parent),
+				"IncompleteKey": r.IncompleteKeyContext(tt.ctx, tt.parent),
+			}
+
+			for name, k := range keys {
+				if k.Namespace != tt.want {
+					t.Fatalf("%s() namespace = %q, want %q", name, k.Namespace, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyFromContextNamespaceRoundTrip(t *testing.T) {
+	ctx := dskit.ContextWithNamespace(context.Background(), "tenant-a")
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	r := fake.NewRepo[tenantNote](c, "Note")
+
+	if _, err := r.CreateWithKey(ctx, r.KeyFromNameContext(ctx, "hello", nil), &tenantNote{Text: "hi"}); err != nil {
+		t.Fatalf("CreateWithKey() error = %v", err)
+	}
+
+	got, err := r.Read(ctx, r.KeyFromNameContext(ctx, "hello", nil))
+	if err != nil || got.Text != "hi" {
+		t.Fatalf("Read() = %+v, %v, want the tenant entity", got, err)
+	}
+
+	if _, err := r.Read(context.Background(), r.KeyFromName("hello", nil)); err == nil {
+		t.Fatal("Read() in the default namespace found the tenant entity")
+	}
+}
@@ -0,0 +1,168 @@
+package dskit
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	q "github.com/huysamen/dskit/query"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/option"
+)
+
+type Option func(*config)
+
+type config struct {
+	projectID      string
+	clientOptions  []option.ClientOption
+	retry          RetryPolicy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		retry: DefaultRetryPolicy(),
+	}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+func WithProjectID(projectID string) Option {
+	return func(c *config) {
+		c.projectID = projectID
+	}
+}
+
+func WithClientOptions(options ...option.ClientOption) Option {
+	return func(c *config) {
+		c.clientOptions = append(c.clientOptions, options...)
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = policy
+	}
+}
+
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+type RepoOption[E any] func(*repo[E])
+
+func WithHooks[E any](hooks Hooks[E]) RepoOption[E] {
+	return func(r *repo[E]) {
+		r.hooks = append(r.hooks, hooks)
+	}
+}
+
+func WithTimestamps[E any]() RepoOption[E] {
+	return func(r *repo[E]) {
+		ts, err := newTimestamps[E]()
+		if err != nil {
+			r.err = errors.Join(r.err, err)
+
+			return
+		}
+
+		r.timestamps = ts
+	}
+}
+
+// WithSoftDelete hides entities whose dskit:"deletedAt" field is set. Reads
+// and queries only match entities that store the property, so entities written
+// before the option was enabled must be migrated with BackfillDeletedAt.
+// Queries gain an equality filter on the property, so every query that also
+// filters or orders on other properties needs a composite index that includes
+// it. Updates and upserts keep the stored deletion time; use Restore to undo it.
+func WithSoftDelete[E any]() RepoOption[E] {
+	return func(r *repo[E]) {
+		deletedAt, err := newDeletedAt[E]()
+		if err != nil {
+			r.err = errors.Join(r.err, err)
+
+			return
+		}
+
+		r.deletedAt = deletedAt
+	}
+}
+
+func WithVersioning[E any]() RepoOption[E] {
+	return func(r *repo[E]) {
+		version, err := newVersion[E]()
+		if err != nil {
+			r.err = errors.Join(r.err, err)
+
+			return
+		}
+
+		r.version = version
+	}
+}
+
+func WithPreallocatedIDs[E any]() RepoOption[E] {
+	return func(r *repo[E]) {
+		r.preallocate = true
+	}
+}
+
+func WithSlowQueryLog[E any](threshold time.Duration, logger *slog.Logger) RepoOption[E] {
+	return func(r *repo[E]) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		r.slowQuery = &slowQueryLog{threshold: threshold, logger: logger}
+	}
+}
+
+func WithSlowQueryExplain[E any](threshold time.Duration, logger *slog.Logger) RepoOption[E] {
+	return func(r *repo[E]) {
+		WithSlowQueryLog[E](threshold, logger)(r)
+
+		r.slowQuery.explain = true
+	}
+}
+
+func WithMismatchPolicy[E any](policy q.MismatchPolicy, handler q.MismatchHandler) RepoOption[E] {
+	return func(r *repo[E]) {
+		var collected *q.Mismatches
+		if r.mismatch != nil {
+			collected = r.mismatch.collected
+		}
+
+		r.mismatch = &mismatchPolicy{policy: policy, handler: handler, collected: collected}
+	}
+}
+
+func WithMismatchCollector[E any](collected *q.Mismatches) RepoOption[E] {
+	return func(r *repo[E]) {
+		if r.mismatch == nil {
+			r.mismatch = &mismatchPolicy{policy: q.CollectMismatches}
+		}
+
+		r.mismatch.collected = collected
+	}
+}
+
+func WithClock[E any](clock func() time.Time) RepoOption[E] {
+	return func(r *repo[E]) {
+		r.clock = clock
+	}
+}
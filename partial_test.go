@@ -0,0 +1,149 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+var errCorrupt = errors.New("corrupt gauge")
+
+type gauge struct {
+	Level   int64
+	Corrupt bool
+}
+
+func (g *gauge) Load(props []datastore.Property) error {
+	if err := datastore.LoadStruct(g, props); err != nil {
+		return err
+	}
+
+	if g.Corrupt {
+		return errCorrupt
+	}
+
+	return nil
+}
+
+func (g *gauge) Save() ([]datastore.Property, error) {
+	return datastore.SaveStruct(g)
+}
+
+// newGauges stores a readable gauge and a corrupt one, and returns their keys
+// followed by a key that was never written.
+func newGauges(t *testing.T) (dskit.Repo[gauge], []*datastore.Key) {
+	t.Helper()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	r := fake.NewRepo[gauge](c, "Gauge")
+	ctx := context.Background()
+
+	found, err := r.Create(ctx, nil, &gauge{Level: 1})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	failed := datastore.IDKey("Gauge", found.ID+1, nil)
+	if _, err := c.Client().Put(ctx, failed, &datastore.PropertyList{{Name: "Level", Value: int64(2)}, {Name: "Corrupt", Value: true}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	return r, []*datastore.Key{found, datastore.IDKey("Gauge", found.ID+2, nil), failed}
+}
+
+func TestReadMultiPartial(t *testing.T) {
+	ctx := context.Background()
+	r, keys := newGauges(t)
+
+	entities, statuses, err := r.ReadMultiPartial(ctx, keys)
+	if err != nil {
+		t.Fatalf("ReadMultiPartial() error = %v", err)
+	}
+
+	want := []q.ReadState{q.ReadFound, q.ReadMissing, q.ReadFailed}
+
+	for i, s := range statuses {
+		if !s.Key.Equal(keys[i]) || s.State != want[i] {
+			t.Fatalf("status %d = %v %v, want %v %v", i, s.Key, s.State, keys[i], want[i])
+		}
+
+		if s.Loaded() != (entities[i] != nil) {
+			t.Fatalf("entity %d = %v, want loaded %v", i, entities[i], s.Loaded())
+		}
+	}
+
+	if entities[0].Level != 1 {
+		t.Fatalf("entity 0 Level = %d, want 1", entities[0].Level)
+	}
+
+	if statuses[1].Err == nil || !errors.Is(statuses[2].Err, errCorrupt) {
+		t.Fatalf("status errors = %v, %v, want not found and %v", statuses[1].Err, statuses[2].Err, errCorrupt)
+	}
+}
+
+func TestReadFailures(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		keys   func(keys []*datastore.Key) []*datastore.Key
+		found  int
+		failed []int
+	}{
+		{name: "found and missing", keys: func(keys []*datastore.Key) []*datastore.Key { return keys[:2] }, found: 1},
+		{name: "missing only", keys: func(keys []*datastore.Key) []*datastore.Key { return keys[1:2] }},
+		{name: "found missing and failed", keys: func(keys []*datastore.Key) []*datastore.Key { return keys }, found: 1, failed: []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, keys := newGauges(t)
+			keys = tt.keys(keys)
+
+			got, err := r.ReadMultiMap(ctx, keys)
+			if len(got) != tt.found {
+				t.Fatalf("ReadMultiMap() returned %d entities, want %d", len(got), tt.found)
+			}
+
+			if got[keys[0].Encode()] == nil && tt.found > 0 {
+				t.Fatalf("ReadMultiMap() is missing %v", keys[0])
+			}
+
+			if len(tt.failed) == 0 {
+				if err != nil {
+					t.Fatalf("ReadMultiMap() error = %v, want nil for missing keys", err)
+				}
+
+				return
+			}
+
+			var me datastore.MultiError
+			if !errors.As(err, &me) || len(me) != len(keys) {
+				t.Fatalf("ReadMultiMap() error = %v, want a MultiError over %d keys", err, len(keys))
+			}
+
+			for i, e := range me {
+				failed := false
+
+				for _, f := range tt.failed {
+					failed = failed || f == i
+				}
+
+				if failed != (e != nil) {
+					t.Fatalf("ReadMultiMap() error %d = %v, want failed %v", i, e, failed)
+				}
+			}
+
+			if !errors.Is(err, errCorrupt) || errors.Is(err, q.ErrNotFound) {
+				t.Fatalf("ReadMultiMap() error = %v, want %v without not found", err, errCorrupt)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package dskit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+)
+
+func FieldPatch[E any](fields map[string]any) (func(*E) error, error) {
+	t := reflect.TypeFor[E]()
+
+	if t.Kind() != reflect.Struct {
+		return nil, &q.ArgumentError{Argument: "entity", Reason: fmt.Sprintf("must be a struct to patch fields, got %s", t)}
+	}
+
+	properties := make(map[string]reflect.StructField)
+
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous || f.Tag.Get("datastore") == "-" {
+			continue
+		}
+
+		properties[propertyName(f)] = f
+	}
+
+	type assignment struct {
+		index []int
+		value reflect.Value
+	}
+
+	assignments := make([]assignment, 0, len(fields))
+
+	for name, value := range fields {
+		f, ok := properties[name]
+		if !ok {
+			return nil, &q.ArgumentError{Argument: "field " + name, Reason: fmt.Sprintf("does not exist on %s", t)}
+		}
+
+		v, err := assignable(f, value)
+		if err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, assignment{index: f.Index, value: v})
+	}
+
+	return func(entity *E) error {
+		if entity == nil {
+			return &q.ArgumentError{Argument: "entity pointer", Reason: "is nil"}
+		}
+
+		v := reflect.ValueOf(entity).Elem()
+
+		for _, a := range assignments {
+			dst, err := v.FieldByIndexErr(a.index)
+			if err != nil {
+				return &q.ArgumentError{Argument: "entity", Reason: err.Error()}
+			}
+
+			dst.Set(a.value)
+		}
+
+		return nil
+	}, nil
+}
+
+func assignable(f reflect.StructField, value any) (reflect.Value, error) {
+	if value == nil {
+		switch f.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			return reflect.Zero(f.Type), nil
+		}
+
+		return reflect.Value{}, &q.ArgumentError{Argument: "field " + f.Name, Reason: fmt.Sprintf("of type %s cannot be nil", f.Type)}
+	}
+
+	v := reflect.ValueOf(value)
+
+	if v.Type().AssignableTo(f.Type) {
+		return v, nil
+	}
+
+	if numeric(v.Kind()) && numeric(f.Type.Kind()) && v.CanConvert(f.Type) {
+		return v.Convert(f.Type), nil
+	}
+
+	return reflect.Value{}, &q.ArgumentError{Argument: "field " + f.Name, Reason: fmt.Sprintf("has type %s, got %s", f.Type, v.Type())}
+}
+
+func numeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *repo[E]) Patch(ctx context.Context, key *datastore.Key, patch func(*E) error) (out *E, err error) {
+	ctx, span := r.start(ctx, nil, "Patch", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	var patched *E
+
+	if _, err := r.client.RunInTransaction(ctx, func(txn Transaction) error {
+		e, err := r.PatchTxn(txn, key, patch)
+		patched = e
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+func (r *repo[E]) PatchTxn(txn q.Transaction, key *datastore.Key, patch func(*E) error) (out *E, err error) {
+	_, span := r.start(contextOf(txn), txn, "PatchTxn", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	if patch == nil {
+		return nil, &q.ArgumentError{Argument: "patch", Reason: "cannot be nil"}
+	}
+
+	entity, err := r.ReadTxn(txn, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := patch(entity); err != nil {
+		return nil, err
+	}
+
+	if err := r.UpdateTxn(txn, key, entity); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+func (r *repo[E]) PatchMulti(ctx context.Context, keys []*datastore.Key, patch func(*E) error) (out []*E, err error) {
+	ctx, span := r.start(ctx, nil, "PatchMulti", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
+	var patched []*E
+
+	if _, err := r.client.RunInTransaction(ctx, func(txn Transaction) error {
+		e, err := r.PatchMultiTxn(txn, keys, patch)
+		patched = e
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+func (r *repo[E]) PatchMultiTxn(txn q.Transaction, keys []*datastore.Key, patch func(*E) error) (out []*E, err error) {
+	_, span := r.start(contextOf(txn), txn, "PatchMultiTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
+	if patch == nil {
+		return nil, &q.ArgumentError{Argument: "patch", Reason: "cannot be nil"}
+	}
+
+	entities, err := r.ReadMultiTxn(txn, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entities {
+		if err := patch(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.UpdateMultiTxn(txn, keys, entities); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+func (r *repo[E]) PatchFields(ctx context.Context, key *datastore.Key, fields map[string]any) (out *E, err error) {
+	ctx, span := r.start(ctx, nil, "PatchFields", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	patch, err := FieldPatch[E](fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Patch(ctx, key, patch)
+}
+
+func (r *repo[E]) PatchFieldsTxn(txn q.Transaction, key *datastore.Key, fields map[string]any) (out *E, err error) {
+	_, span := r.start(contextOf(txn), txn, "PatchFieldsTxn", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	patch, err := FieldPatch[E](fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.PatchTxn(txn, key, patch)
+}
@@ -0,0 +1,223 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type ticket struct {
+	Title    string
+	Priority int64 `datastore:"prio"`
+	Owner    *string
+	Secret   string `datastore:"-"`
+}
+
+func newTickets(t *testing.T) (*fake.Client, dskit.Repo[ticket], *datastore.Key) {
+	t.Helper()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	r := fake.NewRepo[ticket](c, "Ticket")
+
+	key, err := r.Create(context.Background(), nil, &ticket{Title: "first", Priority: 1})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	return c, r, key
+}
+
+func TestFieldPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]any
+		want    ticket
+		wantErr error
+	}{
+		{name: "property name", fields: map[string]any{"Title": "second"}, want: ticket{Title: "second", Priority: 1}},
+		{name: "datastore tag name", fields: map[string]any{"prio": 5}, want: ticket{Title: "first", Priority: 5}},
+		{name: "nil pointer", fields: map[string]any{"Owner": nil}, want: ticket{Title: "first", Priority: 1}},
+		{name: "go name of a renamed field", fields: map[string]any{"Priority": 5}, wantErr: q.ErrInvalidArgument},
+		{name: "unknown field", fields: map[string]any{"Missing": "x"}, wantErr: q.ErrInvalidArgument},
+		{name: "ignored field", fields: map[string]any{"Secret": "x"}, wantErr: q.ErrInvalidArgument},
+		{name: "nested path", fields: map[string]any{"Owner.Name": "x"}, wantErr: q.ErrInvalidArgument},
+		{name: "wrong type", fields: map[string]any{"Title": 5}, wantErr: q.ErrInvalidArgument},
+		{name: "nil for a value field", fields: map[string]any{"Title": nil}, wantErr: q.ErrInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := dskit.FieldPatch[ticket](tt.fields)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FieldPatch() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			owner := "ada"
+			got := ticket{Title: "first", Priority: 1, Owner: &owner}
+
+			if err := patch(&got); err != nil {
+				t.Fatalf("patch() error = %v", err)
+			}
+
+			if _, ok := tt.fields["Owner"]; ok != (got.Owner == nil) {
+				t.Fatalf("patch() Owner = %v, want cleared %v", got.Owner, ok)
+			}
+
+			got.Owner = nil
+
+			if got != tt.want {
+				t.Fatalf("patch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	tests := []struct {
+		name    string
+		patch   func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error)
+		missing bool
+		want    ticket
+		wantErr error
+	}{
+		{
+			name: "Patch",
+			patch: func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error) {
+				return r.Patch(ctx, key, func(e *ticket) error {
+					e.Priority++
+
+					return nil
+				})
+			},
+			want: ticket{Title: "first", Priority: 2},
+		},
+		{
+			name: "PatchFields",
+			patch: func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error) {
+				return r.PatchFields(ctx, key, map[string]any{"Title": "second", "prio": int32(3)})
+			},
+			want: ticket{Title: "second", Priority: 3},
+		},
+		{
+			name: "PatchFields unknown field",
+			patch: func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error) {
+				return r.PatchFields(ctx, key, map[string]any{"Missing": "x"})
+			},
+			want:    ticket{Title: "first", Priority: 1},
+			wantErr: q.ErrInvalidArgument,
+		},
+		{
+			name: "patch error aborts the write",
+			patch: func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error) {
+				return r.Patch(ctx, key, func(e *ticket) error {
+					e.Title = "lost"
+
+					return errAbort
+				})
+			},
+			want:    ticket{Title: "first", Priority: 1},
+			wantErr: errAbort,
+		},
+		{
+			name: "nil patch",
+			patch: func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error) {
+				return r.Patch(ctx, key, nil)
+			},
+			want:    ticket{Title: "first", Priority: 1},
+			wantErr: q.ErrInvalidArgument,
+		},
+		{
+			name: "missing entity",
+			patch: func(ctx context.Context, r dskit.Repo[ticket], key *datastore.Key) (*ticket, error) {
+				return r.PatchFields(ctx, key, map[string]any{"Title": "second"})
+			},
+			missing: true,
+			wantErr: q.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, r, key := newTickets(t)
+
+			if tt.missing {
+				key = datastore.IDKey("Ticket", key.ID+1, nil)
+			}
+
+			got, err := tt.patch(ctx, r, key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("patch error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.missing {
+				if c.Store().Len() != 1 {
+					t.Fatalf("store holds %d entities, want 1", c.Store().Len())
+				}
+
+				return
+			}
+
+			if err == nil && *got != tt.want {
+				t.Fatalf("patch = %+v, want %+v", *got, tt.want)
+			}
+
+			stored, err := r.Read(ctx, key)
+			if err != nil || *stored != tt.want {
+				t.Fatalf("Read() = %+v, %v, want %+v", stored, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchRetry(t *testing.T) {
+	ctx := context.Background()
+	_, r, key := newTickets(t)
+
+	attempts := 0
+
+	got, err := r.Patch(ctx, key, func(e *ticket) error {
+		attempts++
+
+		if attempts == 1 {
+			if _, err := r.Upsert(ctx, key, &ticket{Title: "concurrent", Priority: 10}); err != nil {
+				return err
+			}
+		}
+
+		e.Priority++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Patch() error = %v after %d attempts", err, attempts)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+
+	want := ticket{Title: "concurrent", Priority: 11}
+
+	if *got != want {
+		t.Fatalf("Patch() = %+v, want %+v", *got, want)
+	}
+
+	stored, err := r.Read(ctx, key)
+	if err != nil || *stored != want {
+		t.Fatalf("Read() = %+v, %v, want %+v", stored, err, want)
+	}
+}
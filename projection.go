@@ -0,0 +1,341 @@
+package dskit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+)
+
+var (
+	geoPointType          = reflect.TypeFor[datastore.GeoPoint]()
+	keyType               = reflect.TypeFor[datastore.Key]()
+	propertyLoadSaverType = reflect.TypeFor[datastore.PropertyLoadSaver]()
+)
+
+func ProjectionFields[P any]() ([]string, error) {
+	t := reflect.TypeFor[P]()
+
+	if t.Kind() != reflect.Struct {
+		return nil, &q.ArgumentError{Argument: "projection", Reason: fmt.Sprintf("must be a struct, got %s", t)}
+	}
+
+	fields := projectionPaths(t, "", nil)
+
+	if len(fields) == 0 {
+		return nil, &q.ArgumentError{Argument: "projection", Reason: fmt.Sprintf("%s has no projectable fields", t)}
+	}
+
+	return fields, nil
+}
+
+func projectionPaths(t reflect.Type, prefix string, fields []string) []string {
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		if (!f.IsExported() && !f.Anonymous) || f.Tag.Get("datastore") == "-" {
+			continue
+		}
+
+		name := propertyName(f)
+		if name == "__key__" {
+			continue
+		}
+
+		nested := f.Type
+		for nested.Kind() == reflect.Pointer || nested.Kind() == reflect.Slice {
+			nested = nested.Elem()
+		}
+
+		switch {
+		case !nestedStruct(nested):
+			if f.IsExported() {
+				fields = append(fields, prefix+name)
+			}
+		case f.Anonymous && !hasPropertyName(f):
+			fields = projectionPaths(nested, prefix, fields)
+		case f.IsExported():
+			fields = projectionPaths(nested, prefix+name+".", fields)
+		}
+	}
+
+	return fields
+}
+
+func nestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType || t == geoPointType || t == keyType {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(propertyLoadSaverType)
+}
+
+func hasPropertyName(f reflect.StructField) bool {
+	name, _, _ := strings.Cut(f.Tag.Get("datastore"), ",")
+
+	return name != ""
+}
+
+func ProjectFind[E, P any](ctx context.Context, repo Repo[E], spec *q.Spec) ([]*P, *datastore.Cursor, error) {
+	fields, err := ProjectionFields[P]()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entities, next, err := repo.FindProjection(ctx, spec, fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := loadProjections[P](ctx, entities)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out, next, nil
+}
+
+func ProjectFindTxn[E, P any](ctx context.Context, repo Repo[E], txn q.Transaction, spec *q.Spec) ([]*P, *datastore.Cursor, error) {
+	fields, err := ProjectionFields[P]()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entities, next, err := repo.FindProjectionTxn(ctx, txn, spec, fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := loadProjections[P](ctx, entities)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out, next, nil
+}
+
+func ProjectList[E, P any](ctx context.Context, repo Repo[E], ancestor *datastore.Key, limit int, cursor string) ([]*P, *datastore.Cursor, error) {
+	return ProjectFind[E, P](ctx, repo, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+}
+
+func ProjectListTxn[E, P any](ctx context.Context, repo Repo[E], txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*P, *datastore.Cursor, error) {
+	return ProjectFindTxn[E, P](ctx, repo, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+}
+
+func ProjectPage[E, P any](ctx context.Context, repo Repo[E], ancestor *datastore.Key, limit int, offset int) ([]*P, *datastore.Cursor, error) {
+	return ProjectFind[E, P](ctx, repo, q.NewSpec().Ancestor(ancestor).Limit(limit).Offset(offset))
+}
+
+func ProjectPageTxn[E, P any](ctx context.Context, repo Repo[E], txn q.Transaction, ancestor *datastore.Key, limit int, offset int) ([]*P, *datastore.Cursor, error) {
+	return ProjectFindTxn[E, P](ctx, repo, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Offset(offset))
+}
+
+func ProjectAll[E, P any](ctx context.Context, repo Repo[E], ancestor *datastore.Key) ([]*P, error) {
+	out, _, err := ProjectFind[E, P](ctx, repo, q.NewSpec().Ancestor(ancestor))
+
+	return out, err
+}
+
+func ProjectAllTxn[E, P any](ctx context.Context, repo Repo[E], txn q.Transaction, ancestor *datastore.Key) ([]*P, error) {
+	out, _, err := ProjectFindTxn[E, P](ctx, repo, txn, q.NewSpec().Ancestor(ancestor))
+
+	return out, err
+}
+
+func loadProjections[P any](ctx context.Context, entities []*datastore.Entity) ([]*P, error) {
+	out := make([]*P, len(entities))
+
+	for i, e := range entities {
+		out[i] = new(P)
+
+		if err := q.HandleFieldMismatch(ctx, e.Key, datastore.LoadStruct(out[i], e.Properties)); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+func generated(ctx context.Context, entities []*datastore.Entity, next *datastore.Cursor, generate q.Generator[any]) ([]*any, *datastore.Cursor, error) {
+	if generate == nil {
+		return nil, nil, &q.ArgumentError{Argument: "generator", Reason: "cannot be nil"}
+	}
+
+	out := make([]*any, len(entities))
+
+	for i, e := range entities {
+		dst := generate()
+		if dst == nil {
+			return nil, nil, &q.ArgumentError{Argument: "generator", Reason: "returned nil"}
+		}
+
+		if err := q.HandleFieldMismatch(ctx, e.Key, datastore.LoadStruct(*dst, e.Properties)); err != nil {
+			return nil, nil, err
+		}
+
+		out[i] = dst
+	}
+
+	return out, next, nil
+}
+
+func (r *repo[E]) FindProjection(ctx context.Context, spec *q.Spec, fields ...string) (out []*datastore.Entity, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "FindProjection")
+	defer func() { endPage(span, err, out, next) }()
+
+	query, err := r.projectionQuery(ctx, spec, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	started := time.Now()
+	defer func() { r.logSlow(ctx, nil, "FindProjection", spec, query, started, err) }()
+
+	entities, next, err := q.Project(ctx, r.client, query, newKeyedProperties, fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asEntities(entities), next, nil
+}
+
+func (r *repo[E]) FindProjectionTxn(ctx context.Context, txn q.Transaction, spec *q.Spec, fields ...string) (out []*datastore.Entity, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "FindProjectionTxn")
+	defer func() { endPage(span, err, out, next) }()
+
+	query, err := r.projectionQuery(ctx, spec, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	started := time.Now()
+	defer func() { r.logSlow(ctx, txn, "FindProjectionTxn", spec, query, started, err) }()
+
+	entities, next, err := q.ProjectTxn(ctx, txn, r.client, query, newKeyedProperties, fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asEntities(entities), next, nil
+}
+
+func (r *repo[E]) projectionQuery(ctx context.Context, spec *q.Spec, fields []string) (*datastore.Query, error) {
+	if len(fields) == 0 {
+		return nil, &q.ArgumentError{Argument: "fields", Reason: "must contain at least one field"}
+	}
+
+	return r.query(ctx, spec.Project(fields...))
+}
+
+func (r *repo[E]) ListProjection(ctx context.Context, ancestor *datastore.Key, limit int, cursor string, generate q.Generator[any], fields ...string) (out []*any, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "ListProjection", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	entities, next, err := r.FindProjection(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor), fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return generated(ctx, entities, next, generate)
+}
+
+func (r *repo[E]) ListProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string, generate q.Generator[any], fields ...string) (out []*any, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "ListProjectionTxn", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	entities, next, err := r.FindProjectionTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor), fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return generated(ctx, entities, next, generate)
+}
+
+func (r *repo[E]) ListPageProjection(ctx context.Context, ancestor *datastore.Key, limit int, offset int, generate q.Generator[any], fields ...string) (out []*any, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "ListPageProjection")
+	defer func() { endPage(span, err, out, next) }()
+
+	entities, next, err := r.FindProjection(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Offset(offset), fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return generated(ctx, entities, next, generate)
+}
+
+func (r *repo[E]) ListPageProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, offset int, generate q.Generator[any], fields ...string) (out []*any, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "ListPageProjectionTxn")
+	defer func() { endPage(span, err, out, next) }()
+
+	entities, next, err := r.FindProjectionTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Offset(offset), fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return generated(ctx, entities, next, generate)
+}
+
+func (r *repo[E]) ListAllProjection(ctx context.Context, ancestor *datastore.Key, generate q.Generator[any], fields ...string) (out []*any, err error) {
+	ctx, span := r.start(ctx, nil, "ListAllProjection")
+	defer func() { end(span, err, out) }()
+
+	entities, _, err := r.FindProjection(ctx, q.NewSpec().Ancestor(ancestor), fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, _, err = generated(ctx, entities, nil, generate)
+
+	return out, err
+}
+
+func (r *repo[E]) ListAllProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, generate q.Generator[any], fields ...string) (out []*any, err error) {
+	ctx, span := r.start(ctx, txn, "ListAllProjectionTxn")
+	defer func() { end(span, err, out) }()
+
+	entities, _, err := r.FindProjectionTxn(ctx, txn, q.NewSpec().Ancestor(ancestor), fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, _, err = generated(ctx, entities, nil, generate)
+
+	return out, err
+}
+
+type keyedProperties datastore.Entity
+
+func newKeyedProperties() *keyedProperties {
+	return new(keyedProperties)
+}
+
+func (p *keyedProperties) Load(props []datastore.Property) error {
+	p.Properties = props
+
+	return nil
+}
+
+func (p *keyedProperties) Save() ([]datastore.Property, error) {
+	return p.Properties, nil
+}
+
+func (p *keyedProperties) LoadKey(key *datastore.Key) error {
+	p.Key = key
+
+	return nil
+}
+
+func asEntities(props []*keyedProperties) []*datastore.Entity {
+	out := make([]*datastore.Entity, len(props))
+
+	for i, p := range props {
+		out[i] = (*datastore.Entity)(p)
+	}
+
+	return out
+}
@@ -0,0 +1,118 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type location struct {
+	City    string
+	Country string `datastore:"country"`
+}
+
+type audit struct {
+	CreatedBy string
+}
+
+type customer struct {
+	audit
+	Name     string
+	Address  location
+	Billing  *location `datastore:"billing"`
+	Seen     time.Time
+	Key      *datastore.Key `datastore:"__key__"`
+	Internal string         `datastore:"-"`
+}
+
+type customerCity struct {
+	Name    string
+	Address struct {
+		City string
+	}
+}
+
+func TestProjectionFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  func() ([]string, error)
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "flat struct",
+			fields: dskit.ProjectionFields[audit],
+			want:   []string{"CreatedBy"},
+		},
+		{
+			name:   "nested structs use dotted paths",
+			fields: dskit.ProjectionFields[customer],
+			want:   []string{"CreatedBy", "Name", "Address.City", "Address.country", "billing.City", "billing.country", "Seen"},
+		},
+		{
+			name:   "anonymous nested struct",
+			fields: dskit.ProjectionFields[customerCity],
+			want:   []string{"Name", "Address.City"},
+		},
+		{
+			name:    "not a struct",
+			fields:  dskit.ProjectionFields[string],
+			wantErr: q.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fields()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProjectionFields() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("ProjectionFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectFindNested(t *testing.T) {
+	ctx := context.Background()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	r := fake.NewRepo[customer](c, "Customer")
+
+	for _, e := range []*customer{
+		{Name: "ada", Address: location{City: "London", Country: "UK"}},
+		{Name: "grace", Address: location{City: "New York", Country: "US"}},
+	} {
+		if _, err := r.Create(ctx, nil, e); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	got, _, err := dskit.ProjectFind[customer, customerCity](ctx, r, q.NewSpec().Order("Name"))
+	if err != nil {
+		t.Fatalf("ProjectFind() error = %v", err)
+	}
+
+	want := map[string]string{"ada": "London", "grace": "New York"}
+
+	if len(got) != len(want) {
+		t.Fatalf("ProjectFind() returned %d rows, want %d", len(got), len(want))
+	}
+
+	for _, p := range got {
+		if want[p.Name] != p.Address.City {
+			t.Fatalf("ProjectFind() row %+v, want city %q", p, want[p.Name])
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"cloud.google.com/go/datastore"
+	"cloud.google.com/go/datastore/apiv1/datastorepb"
+)
+
+type aggregateOp int
+
+const (
+	aggregateCount aggregateOp = iota
+	aggregateSum
+	aggregateAvg
+)
+
+type aggregate struct {
+	op      aggregateOp
+	alias   string
+	field   string
+	upTo    int
+	bounded bool
+}
+
+type Aggregation struct {
+	query      *datastore.Query
+	aggregates []aggregate
+}
+
+func Aggregate(query *datastore.Query) *Aggregation {
+	return &Aggregation{query: query}
+}
+
+func (a *Aggregation) with(agg aggregate) *Aggregation {
+	c := *a
+	c.aggregates = append(slices.Clone(a.aggregates), agg)
+
+	return &c
+}
+
+func (a *Aggregation) Count(alias string) *Aggregation {
+	return a.with(aggregate{op: aggregateCount, alias: alias})
+}
+
+func (a *Aggregation) CountUpTo(alias string, limit int) *Aggregation {
+	return a.with(aggregate{op: aggregateCount, alias: alias, upTo: limit, bounded: true})
+}
+
+func (a *Aggregation) Sum(alias, field string) *Aggregation {
+	return a.with(aggregate{op: aggregateSum, alias: alias, field: field})
+}
+
+func (a *Aggregation) Avg(alias, field string) *Aggregation {
+	return a.with(aggregate{op: aggregateAvg, alias: alias, field: field})
+}
+
+func (a *Aggregation) Run(ctx context.Context, client Client, opts ...ReadOption) (out *AggregationResult, err error) {
+	ctx, span := start(ctx, client, "Aggregate")
+	defer func() { finish(span, err, nil) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	txn, err := snapshot(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bounded counts need their own request, so every request reads the same snapshot.
+	if txn == nil && len(a.groups()) > 1 {
+		txn, err = beginSnapshot(ctx, client, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if txn != nil {
+		return a.RunTxn(ctx, txn, client)
+	}
+
+	return a.run(ctx, client, a.query)
+}
+
+func (a *Aggregation) RunTxn(ctx context.Context, txn Transaction, client Client) (out *AggregationResult, err error) {
+	ctx, span := startTxn(ctx, txn, client, "AggregateTxn")
+	defer func() { finish(span, err, nil) }()
+
+	if err := requiresTransaction(txn); err != nil {
+		return nil, err
+	}
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	return a.run(ctx, client, a.query.Transaction(txn.Txn()))
+}
+
+func (a *Aggregation) validate() error {
+	if err := requiresQuery(a.query); err != nil {
+		return err
+	}
+
+	if len(a.aggregates) == 0 {
+		return invalid("aggregations", "must contain at least one aggregation")
+	}
+
+	seen := make(map[string]bool, len(a.aggregates))
+
+	for _, agg := range a.aggregates {
+		if agg.alias == "" {
+			return invalid("alias", "cannot be empty")
+		}
+
+		if seen[agg.alias] {
+			return invalid("alias", "%q is used more than once", agg.alias)
+		}
+
+		seen[agg.alias] = true
+
+		if agg.op != aggregateCount && agg.field == "" {
+			return invalid("field", "cannot be empty for alias %q", agg.alias)
+		}
+
+		if agg.bounded && agg.upTo <= 0 {
+			return invalid("limit", "must be positive for alias %q", agg.alias)
+		}
+	}
+
+	return nil
+}
+
+func (a *Aggregation) groups() [][]aggregate {
+	var (
+		unbounded []aggregate
+		bounded   = make(map[int][]aggregate)
+	)
+
+	for _, agg := range a.aggregates {
+		if !agg.bounded {
+			unbounded = append(unbounded, agg)
+
+			continue
+		}
+
+		bounded[agg.upTo] = append(bounded[agg.upTo], agg)
+	}
+
+	var out [][]aggregate
+
+	if len(unbounded) > 0 {
+		out = append(out, unbounded)
+	}
+
+	for _, upTo := range slices.Sorted(maps.Keys(bounded)) {
+		out = append(out, bounded[upTo])
+	}
+
+	return out
+}
+
+func (a *Aggregation) run(ctx context.Context, client Client, query *datastore.Query) (*AggregationResult, error) {
+	values := make(map[string]*datastorepb.Value, len(a.aggregates))
+
+	for _, aggs := range a.groups() {
+		nested := query
+		if aggs[0].bounded {
+			nested = query.Limit(boundedLimit(query, aggs[0].upTo))
+		}
+
+		r, err := runAggregates(ctx, client, nested, aggs)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(values, r)
+	}
+
+	return &AggregationResult{values: values}, nil
+}
+
+// datastore.Query does not expose its limit, so it is read reflectively to keep
+// a caller's Limit in force when a bounded count narrows the nested query.
+func boundedLimit(query *datastore.Query, upTo int) int {
+	limit := reflect.ValueOf(query).Elem().FieldByName("limit")
+	if !limit.IsValid() || !limit.CanInt() || limit.Int() < 0 {
+		return upTo
+	}
+
+	return min(int(limit.Int()), upTo)
+}
+
+func runAggregates(ctx context.Context, client Client, query *datastore.Query, aggs []aggregate) (map[string]*datastorepb.Value, error) {
+	aq := query.NewAggregationQuery()
+
+	for _, agg := range aggs {
+		switch agg.op {
+		case aggregateCount:
+			aq = aq.WithCount(agg.alias)
+		case aggregateSum:
+			aq = aq.WithSum(agg.field, agg.alias)
+		case aggregateAvg:
+			aq = aq.WithAvg(agg.field, agg.alias)
+		}
+	}
+
+	r, err := client.Client().RunAggregationQuery(ctx, aq)
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	values := make(map[string]*datastorepb.Value, len(aggs))
+
+	for _, agg := range aggs {
+		v, err := aggregateValue(r, agg.alias)
+		if err != nil {
+			return nil, err
+		}
+
+		values[agg.alias] = v
+	}
+
+	return values, nil
+}
+
+type AggregationResult struct {
+	values map[string]*datastorepb.Value
+}
+
+func (r *AggregationResult) Int(alias string) (int64, error) {
+	v, err := r.value(alias)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := v.GetValueType().(*datastorepb.Value_IntegerValue)
+	if !ok {
+		return 0, fmt.Errorf("aggregation %q: %w %T for integer result", alias, ErrUnexpectedValue, v.GetValueType())
+	}
+
+	return value.IntegerValue, nil
+}
+
+func (r *AggregationResult) Float(alias string) (float64, error) {
+	v, err := r.value(alias)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := numericValue(v)
+	if err != nil {
+		return 0, fmt.Errorf("aggregation %q: %w", alias, err)
+	}
+
+	return value, nil
+}
+
+func (r *AggregationResult) IsNull(alias string) bool {
+	v, ok := r.values[alias]
+
+	return ok && isNullValue(v)
+}
+
+func (r *AggregationResult) value(alias string) (*datastorepb.Value, error) {
+	v, ok := r.values[alias]
+	if !ok {
+		return nil, invalid("alias", "%q is not part of the aggregation", alias)
+	}
+
+	if isNullValue(v) {
+		return nil, fmt.Errorf("aggregation %q: %w", alias, ErrNullAggregation)
+	}
+
+	return v, nil
+}
+
+func isNullValue(v *datastorepb.Value) bool {
+	_, null := v.GetValueType().(*datastorepb.Value_NullValue)
+
+	return null
+}
@@ -0,0 +1,100 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+func TestAggregationRun(t *testing.T) {
+	ctx := context.Background()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	for i := range 5 {
+		if _, err := c.Client().Put(ctx, datastore.IDKey("Counter", int64(i+1), nil), &counter{N: i + 1}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	query := datastore.NewQuery("Counter")
+
+	tests := []struct {
+		name    string
+		agg     *q.Aggregation
+		want    map[string]float64
+		calls   int
+		wantErr error
+	}{
+		{
+			name: "count sum and avg",
+			agg:  q.Aggregate(query).Count("count").Sum("sum", "N").Avg("avg", "N"),
+			want: map[string]float64{"count": 5, "sum": 15, "avg": 3},
+		},
+		{
+			name: "count respects the query limit",
+			agg:  q.Aggregate(query.Limit(2)).Count("count").Sum("sum", "N"),
+			want: map[string]float64{"count": 2, "sum": 3},
+		},
+		{
+			name: "count up to",
+			agg:  q.Aggregate(query).CountUpTo("a", 3).CountUpTo("b", 3),
+			want: map[string]float64{"a": 3, "b": 3},
+		},
+		{
+			name:  "mixed builder",
+			agg:   q.Aggregate(query).Count("n").CountUpTo("c", 3).Sum("total", "N").Avg("mean", "N"),
+			want:  map[string]float64{"n": 5, "c": 3, "total": 15, "mean": 3},
+			calls: 2,
+		},
+		{
+			name:  "count up to keeps a lower query limit",
+			agg:   q.Aggregate(query.Limit(2)).Count("n").CountUpTo("c", 3),
+			want:  map[string]float64{"n": 2, "c": 2},
+			calls: 2,
+		},
+		{
+			name:  "count up to below the query limit",
+			agg:   q.Aggregate(query.Limit(4)).CountUpTo("c", 3).CountUpTo("d", 10),
+			want:  map[string]float64{"c": 3, "d": 4},
+			calls: 2,
+		},
+		{
+			name:    "count up to without a positive limit",
+			agg:     q.Aggregate(query).CountUpTo("c", 0),
+			wantErr: q.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := c.Store().Calls("RunAggregationQuery")
+
+			r, err := tt.agg.Run(ctx, c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantCalls := max(tt.calls, 1)
+			if tt.wantErr != nil {
+				wantCalls = 0
+			}
+
+			if got := c.Store().Calls("RunAggregationQuery") - calls; got != wantCalls {
+				t.Fatalf("Run() issued %d aggregation queries, want %d", got, wantCalls)
+			}
+
+			for alias, want := range tt.want {
+				got, err := r.Float(alias)
+				if err != nil || got != want {
+					t.Fatalf("Float(%q) = %v, %v, want %v", alias, got, err, want)
+				}
+			}
+		})
+	}
+}
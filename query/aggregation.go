@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"cloud.google.com/go/datastore"
-	"cloud.google.com/go/datastore/apiv1/datastorepb"
 )
 
 func QueryAggregations(
@@ -13,62 +12,21 @@ func QueryAggregations(
 	client Client,
 	query *datastore.Query,
 	sumFields, avgFields []string,
-) (map[string]float64, map[string]float64, error) {
-	if err := requiresClient(client); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
-	}
+	opts ...ReadOption,
+) (_ map[string]float64, _ map[string]float64, err error) {
+	ctx, span := start(ctx, client, "QueryAggregations")
+	defer func() { finish(span, err, nil) }()
 
 	if err := requiresOneField(sumFields, avgFields); err != nil {
 		return nil, nil, err
 	}
 
-	aq := query.NewAggregationQuery()
-
-	sums := make(map[string]float64)
-	avgs := make(map[string]float64)
-
-	for _, s := range sumFields {
-		aq = aq.WithSum(s, s)
-	}
-
-	for _, a := range avgFields {
-		aq = aq.WithAvg(a, a)
-	}
-
-	r, err := client.Client().RunAggregationQuery(ctx, aq)
+	r, err := fieldAggregation(query, sumFields, avgFields).Run(ctx, client, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	for _, s := range sumFields {
-		sv := r[s]
-		v := sv.(*datastorepb.Value)
-
-		value, err := numericValue(v)
-		if err != nil {
-			return nil, nil, fmt.Errorf("sum field %q: %w", s, err)
-		}
-
-		sums[s] = value
-	}
-
-	for _, a := range avgFields {
-		av := r[a]
-		v := av.(*datastorepb.Value)
-
-		value, err := numericValue(v)
-		if err != nil {
-			return nil, nil, fmt.Errorf("avg field %q: %w", a, err)
-		}
-
-		avgs[a] = value
-	}
-
-	return sums, avgs, nil
+	return fieldAggregates(r, sumFields, avgFields)
 }
 
 func QueryAggregationsTxn(
@@ -77,66 +35,20 @@ func QueryAggregationsTxn(
 	client Client,
 	query *datastore.Query,
 	sumFields, avgFields []string,
-) (map[string]float64, map[string]float64, error) {
-	if err := requiresTransaction(txn); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresClient(client); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
-	}
+) (_ map[string]float64, _ map[string]float64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "QueryAggregationsTxn")
+	defer func() { finish(span, err, nil) }()
 
 	if err := requiresOneField(sumFields, avgFields); err != nil {
 		return nil, nil, err
 	}
 
-	aq := query.Transaction(txn.Txn()).NewAggregationQuery()
-
-	sums := make(map[string]float64)
-	avgs := make(map[string]float64)
-
-	for _, s := range sumFields {
-		aq = aq.WithSum(s, s)
-	}
-
-	for _, a := range avgFields {
-		aq = aq.WithAvg(a, a)
-	}
-
-	r, err := client.Client().RunAggregationQuery(ctx, aq)
+	r, err := fieldAggregation(query, sumFields, avgFields).RunTxn(ctx, txn, client)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	for _, s := range sumFields {
-		sv := r[s]
-		v := sv.(*datastorepb.Value)
-
-		value, err := numericValue(v)
-		if err != nil {
-			return nil, nil, fmt.Errorf("sum field %q: %w", s, err)
-		}
-
-		sums[s] = value
-	}
-
-	for _, a := range avgFields {
-		av := r[a]
-		v := av.(*datastorepb.Value)
-
-		value, err := numericValue(v)
-		if err != nil {
-			return nil, nil, fmt.Errorf("avg field %q: %w", a, err)
-		}
-
-		avgs[a] = value
-	}
-
-	return sums, avgs, nil
+	return fieldAggregates(r, sumFields, avgFields)
 }
 
 func QueryAggregationsWithCount(
@@ -144,66 +56,21 @@ func QueryAggregationsWithCount(
 	client Client,
 	query *datastore.Query,
 	sumFields, avgFields []string,
-) (int64, map[string]float64, map[string]float64, error) {
-	if err := requiresClient(client); err != nil {
-		return 0, nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return 0, nil, nil, err
-	}
+	opts ...ReadOption,
+) (out int64, _ map[string]float64, _ map[string]float64, err error) {
+	ctx, span := start(ctx, client, "QueryAggregationsWithCount")
+	defer func() { finish(span, err, out) }()
 
 	if err := requiresOneField(sumFields, avgFields); err != nil {
 		return 0, nil, nil, err
 	}
 
-	aq := query.NewAggregationQuery().WithCount("count")
-
-	sums := make(map[string]float64)
-	avgs := make(map[string]float64)
-
-	for _, s := range sumFields {
-		aq = aq.WithSum(s, s)
-	}
-
-	for _, a := range avgFields {
-		aq = aq.WithAvg(a, a)
-	}
-
-	r, err := client.Client().RunAggregationQuery(ctx, aq)
+	r, err := fieldAggregation(query, sumFields, avgFields).Count("count").Run(ctx, client, opts...)
 	if err != nil {
 		return 0, nil, nil, err
 	}
 
-	c := r["count"]
-	v := c.(*datastorepb.Value)
-	count := v.GetIntegerValue()
-
-	for _, s := range sumFields {
-		sv := r[s]
-		v := sv.(*datastorepb.Value)
-
-		value, err := numericValue(v)
-		if err != nil {
-			return 0, nil, nil, fmt.Errorf("sum field %q: %w", s, err)
-		}
-
-		sums[s] = value
-	}
-
-	for _, a := range avgFields {
-		av := r[a]
-		v := av.(*datastorepb.Value)
-
-		value, err := numericValue(v)
-		if err != nil {
-			return 0, nil, nil, fmt.Errorf("avg field %q: %w", a, err)
-		}
-
-		avgs[a] = value
-	}
-
-	return count, sums, avgs, nil
+	return countedAggregates(r, sumFields, avgFields)
 }
 
 func QueryAggregationsWithCountTxn(
@@ -212,64 +79,71 @@ func QueryAggregationsWithCountTxn(
 	client Client,
 	query *datastore.Query,
 	sumFields, avgFields []string,
-) (int64, map[string]float64, map[string]float64, error) {
-	if err := requiresTransaction(txn); err != nil {
-		return 0, nil, nil, err
-	}
+) (out int64, _ map[string]float64, _ map[string]float64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "QueryAggregationsWithCountTxn")
+	defer func() { finish(span, err, out) }()
 
-	if err := requiresClient(client); err != nil {
+	if err := requiresOneField(sumFields, avgFields); err != nil {
 		return 0, nil, nil, err
 	}
 
-	if err := requiresOneField(sumFields, avgFields); err != nil {
+	r, err := fieldAggregation(query, sumFields, avgFields).Count("count").RunTxn(ctx, txn, client)
+	if err != nil {
 		return 0, nil, nil, err
 	}
 
-	aq := query.Transaction(txn.Txn()).NewAggregationQuery().WithCount("count")
+	return countedAggregates(r, sumFields, avgFields)
+}
 
-	sums := make(map[string]float64)
-	avgs := make(map[string]float64)
+func fieldAggregation(query *datastore.Query, sumFields, avgFields []string) *Aggregation {
+	a := Aggregate(query)
 
 	for _, s := range sumFields {
-		aq = aq.WithSum(s, s)
+		a = a.Sum(s, s)
 	}
 
-	for _, a := range avgFields {
-		aq = aq.WithAvg(a, a)
+	for _, f := range avgFields {
+		a = a.Avg(f, f)
 	}
 
-	r, err := client.Client().RunAggregationQuery(ctx, aq)
-	if err != nil {
-		return 0, nil, nil, err
-	}
+	return a
+}
 
-	c := r["count"]
-	v := c.(*datastorepb.Value)
-	count := v.GetIntegerValue()
+func fieldAggregates(r *AggregationResult, sumFields, avgFields []string) (map[string]float64, map[string]float64, error) {
+	sums := make(map[string]float64)
+	avgs := make(map[string]float64)
 
 	for _, s := range sumFields {
-		sv := r[s]
-		v := sv.(*datastorepb.Value)
-
-		value, err := numericValue(v)
+		value, err := r.Float(s)
 		if err != nil {
-			return 0, nil, nil, fmt.Errorf("sum field %q: %w", s, err)
+			return nil, nil, fmt.Errorf("sum field %q: %w", s, err)
 		}
 
 		sums[s] = value
 	}
 
 	for _, a := range avgFields {
-		av := r[a]
-		v := av.(*datastorepb.Value)
-
-		value, err := numericValue(v)
+		value, err := r.Float(a)
 		if err != nil {
-			return 0, nil, nil, fmt.Errorf("avg field %q: %w", a, err)
+			return nil, nil, fmt.Errorf("avg field %q: %w", a, err)
 		}
 
 		avgs[a] = value
 	}
 
+	return sums, avgs, nil
+}
+
+func countedAggregates(r *AggregationResult, sumFields, avgFields []string) (int64, map[string]float64, map[string]float64, error) {
+	count, err := r.Int("count")
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	sums, avgs, err := fieldAggregates(r, sumFields, avgFields)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
 	return count, sums, avgs, nil
 }
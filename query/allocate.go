@@ -0,0 +1,55 @@
+package query
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+)
+
+func AllocateIDs(ctx context.Context, client Client, keys []*datastore.Key) (out []*datastore.Key, err error) {
+	ctx, span := start(ctx, client, "AllocateIDs", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	if err := requiresKeys(keys); err != nil {
+		return nil, err
+	}
+
+	result := make([]*datastore.Key, len(keys))
+
+	err = inBatches(ctx, len(keys), maxMutationBatchSize, func(ctx context.Context, start, end int) error {
+		k, err := client.Client().AllocateIDs(ctx, keys[start:end])
+		if err != nil {
+			return Classify(err)
+		}
+
+		copy(result[start:end], k)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func ReserveIDs(ctx context.Context, client Client, keys []*datastore.Key) (err error) {
+	ctx, span := start(ctx, client, "ReserveIDs", keyAttributes(keys...)...)
+	defer func() { finish(span, err, nil) }()
+
+	if err := requiresClient(client); err != nil {
+		return err
+	}
+
+	if err := requiresKeys(keys); err != nil {
+		return err
+	}
+
+	return inBatches(ctx, len(keys), maxMutationBatchSize, func(ctx context.Context, start, end int) error {
+		return Classify(client.Client().ReserveIDs(ctx, keys[start:end]))
+	})
+}
@@ -4,10 +4,21 @@ import (
 	"context"
 
 	"cloud.google.com/go/datastore"
-	"cloud.google.com/go/datastore/apiv1/datastorepb"
 )
 
-func AverageForField(ctx context.Context, client Client, query *datastore.Query, field string) (float64, error) {
+func AverageForField(ctx context.Context, client Client, query *datastore.Query, field string, opts ...ReadOption) (_ float64, err error) {
+	ctx, span := start(ctx, client, "AverageForField")
+	defer func() { finish(span, err, nil) }()
+
+	txn, err := snapshot(ctx, client, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if txn != nil {
+		return AverageForFieldTxn(ctx, txn, client, query, field)
+	}
+
 	a, err := AverageForFields(ctx, client, query, field)
 	if err != nil {
 		return 0, err
@@ -16,7 +27,10 @@ func AverageForField(ctx context.Context, client Client, query *datastore.Query,
 	return a[field], nil
 }
 
-func AverageForFieldTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, field string) (float64, error) {
+func AverageForFieldTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, field string) (_ float64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "AverageForFieldTxn")
+	defer func() { finish(span, err, nil) }()
+
 	a, err := AverageForFieldsTxn(ctx, txn, client, query, field)
 	if err != nil {
 		return 0, err
@@ -25,7 +39,10 @@ func AverageForFieldTxn(ctx context.Context, txn Transaction, client Client, que
 	return a[field], nil
 }
 
-func AverageForFields(ctx context.Context, client Client, query *datastore.Query, fields ...string) (map[string]float64, error) {
+func AverageForFields(ctx context.Context, client Client, query *datastore.Query, fields ...string) (_ map[string]float64, err error) {
+	ctx, span := start(ctx, client, "AverageForFields")
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresClient(client); err != nil {
 		return nil, err
 	}
@@ -47,12 +64,14 @@ func AverageForFields(ctx context.Context, client Client, query *datastore.Query
 
 	r, err := client.Client().RunAggregationQuery(ctx, aq)
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	for _, f := range fields {
-		av := r[f]
-		v := av.(*datastorepb.Value)
+		v, err := aggregateValue(r, f)
+		if err != nil {
+			return nil, err
+		}
 
 		avgs[f] = v.GetDoubleValue()
 	}
@@ -60,7 +79,10 @@ func AverageForFields(ctx context.Context, client Client, query *datastore.Query
 	return avgs, nil
 }
 
-func AverageForFieldsTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, fields ...string) (map[string]float64, error) {
+func AverageForFieldsTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, fields ...string) (_ map[string]float64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "AverageForFieldsTxn")
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return nil, err
 	}
@@ -86,12 +108,14 @@ func AverageForFieldsTxn(ctx context.Context, txn Transaction, client Client, qu
 
 	r, err := client.Client().RunAggregationQuery(ctx, aq)
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	for _, f := range fields {
-		av := r[f]
-		v := av.(*datastorepb.Value)
+		v, err := aggregateValue(r, f)
+		if err != nil {
+			return nil, err
+		}
 
 		avgs[f] = v.GetDoubleValue()
 	}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/datastore"
+)
+
+type BatchError struct {
+	datastore.MultiError
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.MultiError))
+
+	for _, err := range e.MultiError {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (e *BatchError) As(target any) bool {
+	me, ok := target.(*datastore.MultiError)
+	if ok {
+		*me = e.MultiError
+	}
+
+	return ok
+}
+
+func inBatches(ctx context.Context, n, size int, fn func(ctx context.Context, start, end int) error) error {
+	b := &batchErrors{errs: make(datastore.MultiError, n), batches: max((n+size-1)/size, 1)}
+
+	if n <= size {
+		b.record(0, n, fn(ctx, 0, n))
+
+		return b.err()
+	}
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, defaultBatchParallelism)
+
+	for start := 0; start < n; start += size {
+		end := min(start+size, n)
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			b.record(start, end, fn(ctx, start, end))
+		}()
+	}
+
+	wg.Wait()
+
+	return b.err()
+}
+
+type batchErrors struct {
+	mu       sync.Mutex
+	errs     datastore.MultiError
+	batches  int
+	failed   int
+	perIndex bool
+	first    error
+	start    int
+}
+
+func (b *batchErrors) record(start, end int, err error) {
+	if err == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failed++
+
+	if me, ok := multiError(err); ok && len(me) == end-start {
+		b.perIndex = true
+		copy(b.errs[start:end], me)
+
+		return
+	}
+
+	if b.first == nil || start < b.start {
+		b.first, b.start = err, start
+	}
+
+	for i := start; i < end; i++ {
+		b.errs[i] = err
+	}
+}
+
+func (b *batchErrors) err() error {
+	switch {
+	case b.failed == 0:
+		return nil
+	case !b.perIndex && b.failed == b.batches:
+		return b.first
+	default:
+		return &BatchError{MultiError: b.errs}
+	}
+}
@@ -0,0 +1,171 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInBatches(t *testing.T) {
+	errRPC := errors.New("rpc failed")
+
+	tests := []struct {
+		name    string
+		n       int
+		size    int
+		fail    func(start, end int) error
+		wantErr map[int]error
+		wantIs  []error
+		raw     bool
+	}{
+		{
+			name: "single batch succeeds",
+			n:    3,
+			size: 500,
+			fail: func(int, int) error { return nil },
+		},
+		{
+			name:   "single batch rpc error",
+			n:      3,
+			size:   500,
+			fail:   func(int, int) error { return errRPC },
+			wantIs: []error{errRPC},
+			raw:    true,
+		},
+		{
+			name:   "single batch canceled",
+			n:      3,
+			size:   500,
+			fail:   func(int, int) error { return Classify(context.Canceled) },
+			wantIs: []error{context.Canceled},
+			raw:    true,
+		},
+		{
+			name: "single batch per item errors",
+			n:    3,
+			size: 500,
+			fail: func(int, int) error {
+				return datastore.MultiError{nil, datastore.ErrNoSuchEntity, nil}
+			},
+			wantErr: map[int]error{1: datastore.ErrNoSuchEntity},
+			wantIs:  []error{datastore.ErrNoSuchEntity},
+		},
+		{
+			name:   "several batches all unavailable",
+			n:      5,
+			size:   2,
+			fail:   func(int, int) error { return Classify(status.Error(codes.Unavailable, "down")) },
+			wantIs: []error{ErrUnavailable},
+			raw:    true,
+		},
+		{
+			name: "several batches deadline in one",
+			n:    5,
+			size: 2,
+			fail: func(start, _ int) error {
+				if start == 0 {
+					return Classify(context.DeadlineExceeded)
+				}
+
+				return nil
+			},
+			wantErr: map[int]error{0: ErrDeadline, 1: ErrDeadline},
+			wantIs:  []error{ErrDeadline, context.DeadlineExceeded},
+		},
+		{
+			name: "several batches one rpc error",
+			n:    5,
+			size: 2,
+			fail: func(start, _ int) error {
+				if start == 2 {
+					return errRPC
+				}
+
+				return nil
+			},
+			wantErr: map[int]error{2: errRPC, 3: errRPC},
+			wantIs:  []error{errRPC},
+		},
+		{
+			name: "several batches per item errors",
+			n:    5,
+			size: 2,
+			fail: func(start, _ int) error {
+				if start == 4 {
+					return datastore.MultiError{datastore.ErrNoSuchEntity}
+				}
+
+				return nil
+			},
+			wantErr: map[int]error{4: datastore.ErrNoSuchEntity},
+			wantIs:  []error{datastore.ErrNoSuchEntity},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				covered = make([]int, tt.n)
+			)
+
+			err := inBatches(context.Background(), tt.n, tt.size, func(_ context.Context, start, end int) error {
+				if end-start > tt.size {
+					t.Errorf("batch [%d, %d) exceeds size %d", start, end, tt.size)
+				}
+
+				mu.Lock()
+				for i := start; i < end; i++ {
+					covered[i]++
+				}
+				mu.Unlock()
+
+				return tt.fail(start, end)
+			})
+
+			for i, c := range covered {
+				if c != 1 {
+					t.Fatalf("item %d visited %d times", i, c)
+				}
+			}
+
+			if tt.wantIs == nil {
+				if err != nil {
+					t.Fatalf("inBatches() error = %v", err)
+				}
+
+				return
+			}
+
+			for _, want := range tt.wantIs {
+				if !errors.Is(err, want) {
+					t.Fatalf("errors.Is(%v, %v) = false", err, want)
+				}
+			}
+
+			var me datastore.MultiError
+			if ok := errors.As(err, &me); ok == tt.raw {
+				t.Fatalf("inBatches() error = %#v, want per-index errors %v", err, !tt.raw)
+			}
+
+			if tt.raw {
+				return
+			}
+
+			if len(me) != tt.n {
+				t.Fatalf("inBatches() error = %#v, want MultiError of length %d", err, tt.n)
+			}
+
+			for i, e := range me {
+				if want := tt.wantErr[i]; !errors.Is(e, want) || (want == nil) != (e == nil) {
+					t.Fatalf("item %d error = %v, want %v", i, e, want)
+				}
+			}
+		})
+	}
+}
@@ -2,4 +2,7 @@ package query
 
 const (
 	defaultQueryAllocationSize = 16
+	maxMutationBatchSize       = 500
+	maxLookupBatchSize         = 1000
+	defaultBatchParallelism    = 4
 )
@@ -4,10 +4,21 @@ import (
 	"context"
 
 	"cloud.google.com/go/datastore"
-	"cloud.google.com/go/datastore/apiv1/datastorepb"
 )
 
-func CountForQuery(ctx context.Context, client Client, query *datastore.Query) (int64, error) {
+func CountForQuery(ctx context.Context, client Client, query *datastore.Query, opts ...ReadOption) (out int64, err error) {
+	ctx, span := start(ctx, client, "CountForQuery")
+	defer func() { finish(span, err, out) }()
+
+	txn, err := snapshot(ctx, client, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if txn != nil {
+		return CountForQueryTxn(ctx, txn, client, query)
+	}
+
 	if err := requiresClient(client); err != nil {
 		return 0, err
 	}
@@ -18,16 +29,21 @@ func CountForQuery(ctx context.Context, client Client, query *datastore.Query) (
 
 	r, err := client.Client().RunAggregationQuery(ctx, query.NewAggregationQuery().WithCount("count"))
 	if err != nil {
-		return 0, err
+		return 0, Classify(err)
 	}
 
-	c := r["count"]
-	v := c.(*datastorepb.Value)
+	v, err := aggregateValue(r, "count")
+	if err != nil {
+		return 0, err
+	}
 
 	return v.GetIntegerValue(), nil
 }
 
-func CountForQueryTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (int64, error) {
+func CountForQueryTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (out int64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "CountForQueryTxn")
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return 0, err
 	}
@@ -42,11 +58,13 @@ func CountForQueryTxn(ctx context.Context, txn Transaction, client Client, query
 
 	r, err := client.Client().RunAggregationQuery(ctx, query.Transaction(txn.Txn()).NewAggregationQuery().WithCount("count"))
 	if err != nil {
-		return 0, err
+		return 0, Classify(err)
 	}
 
-	c := r["count"]
-	v := c.(*datastorepb.Value)
+	v, err := aggregateValue(r, "count")
+	if err != nil {
+		return 0, err
+	}
 
 	return v.GetIntegerValue(), nil
 }
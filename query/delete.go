@@ -6,7 +6,10 @@ import (
 	"cloud.google.com/go/datastore"
 )
 
-func Delete(ctx context.Context, client Client, key *datastore.Key) error {
+func Delete(ctx context.Context, client Client, key *datastore.Key) (err error) {
+	ctx, span := start(ctx, client, "Delete", keyAttributes(key)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresClient(client); err != nil {
 		return err
 	}
@@ -15,10 +18,13 @@ func Delete(ctx context.Context, client Client, key *datastore.Key) error {
 		return err
 	}
 
-	return client.Client().Delete(ctx, key)
+	return Classify(client.Client().Delete(ctx, key))
 }
 
-func DeleteTxn(txn Transaction, key *datastore.Key) error {
+func DeleteTxn(txn Transaction, key *datastore.Key) (err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "DeleteTxn", keyAttributes(key)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return err
 	}
@@ -27,10 +33,13 @@ func DeleteTxn(txn Transaction, key *datastore.Key) error {
 		return err
 	}
 
-	return txn.Txn().Delete(key)
+	return Classify(txn.Txn().Delete(key))
 }
 
-func DeleteMulti(ctx context.Context, client Client, keys []*datastore.Key) error {
+func DeleteMulti(ctx context.Context, client Client, keys []*datastore.Key) (err error) {
+	ctx, span := start(ctx, client, "DeleteMulti", keyAttributes(keys...)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresClient(client); err != nil {
 		return err
 	}
@@ -39,10 +48,15 @@ func DeleteMulti(ctx context.Context, client Client, keys []*datastore.Key) erro
 		return err
 	}
 
-	return client.Client().DeleteMulti(ctx, keys)
+	return inBatches(ctx, len(keys), maxMutationBatchSize, func(ctx context.Context, start, end int) error {
+		return Classify(client.Client().DeleteMulti(ctx, keys[start:end]))
+	})
 }
 
-func DeleteMultiTxn(txn Transaction, keys []*datastore.Key) error {
+func DeleteMultiTxn(txn Transaction, keys []*datastore.Key) (err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "DeleteMultiTxn", keyAttributes(keys...)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return err
 	}
@@ -51,5 +65,5 @@ func DeleteMultiTxn(txn Transaction, keys []*datastore.Key) error {
 		return err
 	}
 
-	return txn.Txn().DeleteMulti(keys)
+	return Classify(txn.Txn().DeleteMulti(keys))
 }
@@ -0,0 +1,218 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrNotFound        = errors.New("entity not found")
+	ErrConflict        = errors.New("conflict")
+	ErrAlreadyExists   = fmt.Errorf("%w: entity already exists", ErrConflict)
+	ErrContention      = errors.New("transaction contention")
+	ErrUnavailable     = errors.New("service unavailable")
+	ErrDeadline        = errors.New("deadline exceeded")
+	ErrFieldMismatch   = errors.New("field mismatch")
+	ErrAggregation     = errors.New("aggregation failed")
+	ErrNullAggregation = fmt.Errorf("%w: result is null", ErrAggregation)
+	ErrMissingResult   = fmt.Errorf("%w: missing from result", ErrAggregation)
+	ErrUnexpectedValue = fmt.Errorf("%w: unexpected value type", ErrAggregation)
+)
+
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+type ArgumentError struct {
+	Argument string
+	Reason   string
+}
+
+func (e *ArgumentError) Error() string {
+	return e.Argument + " " + e.Reason
+}
+
+func (e *ArgumentError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+func invalid(argument, reason string, args ...any) error {
+	return &ArgumentError{Argument: argument, Reason: fmt.Sprintf(reason, args...)}
+}
+
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if me, ok := multiError(err); ok {
+		classified := make(datastore.MultiError, len(me))
+
+		for i, e := range me {
+			classified[i] = Classify(e)
+		}
+
+		return &BatchError{MultiError: classified}
+	}
+
+	var (
+		typed *Error
+		arg   *ArgumentError
+	)
+
+	if errors.As(err, &typed) || errors.As(err, &arg) {
+		return err
+	}
+
+	kind := classify(err)
+	if kind == nil {
+		return err
+	}
+
+	return &Error{Kind: kind, Err: err}
+}
+
+func multiError(err error) (datastore.MultiError, bool) {
+	switch e := err.(type) {
+	case datastore.MultiError:
+		return e, true
+	case *BatchError:
+		return e.MultiError, true
+	default:
+		return nil, false
+	}
+}
+
+func classify(err error) error {
+	var efm *datastore.ErrFieldMismatch
+
+	switch {
+	case errors.Is(err, datastore.ErrNoSuchEntity):
+		return ErrNotFound
+	case errors.As(err, &efm):
+		return ErrFieldMismatch
+	case errors.Is(err, datastore.ErrConcurrentTransaction):
+		return ErrContention
+	case errors.Is(err, datastore.ErrInvalidKey), errors.Is(err, datastore.ErrInvalidEntityType):
+		return ErrInvalidArgument
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrDeadline
+	}
+
+	switch status.Code(err) {
+	case codes.AlreadyExists:
+		return ErrAlreadyExists
+	case codes.NotFound:
+		return ErrNotFound
+	case codes.Aborted:
+		return ErrContention
+	case codes.Unavailable:
+		return ErrUnavailable
+	case codes.DeadlineExceeded:
+		return ErrDeadline
+	case codes.InvalidArgument:
+		return ErrInvalidArgument
+	default:
+		return nil
+	}
+}
+
+func IsRetryable(err error) bool {
+	err = Classify(err)
+
+	var me datastore.MultiError
+
+	if errors.As(err, &me) {
+		failed := false
+
+		for _, e := range me {
+			if e == nil {
+				continue
+			}
+
+			if !IsRetryable(e) {
+				return false
+			}
+
+			failed = true
+		}
+
+		return failed
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return errors.Is(err, ErrContention) || errors.Is(err, ErrUnavailable) || errors.Is(err, ErrDeadline)
+}
+
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	err = Classify(err)
+
+	var me datastore.MultiError
+
+	if errors.As(err, &me) {
+		class := ""
+
+		for _, e := range me {
+			if e == nil {
+				continue
+			}
+
+			if c := ErrorClass(e); class == "" {
+				class = c
+			} else if c != class {
+				return "mixed"
+			}
+		}
+
+		if class != "" {
+			return class
+		}
+	}
+
+	switch {
+	case errors.Is(err, ErrInvalidArgument):
+		return "invalid_argument"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrAlreadyExists):
+		return "already_exists"
+	case errors.Is(err, ErrConflict):
+		return "conflict"
+	case errors.Is(err, ErrContention):
+		return "contention"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, ErrDeadline):
+		return "deadline"
+	case errors.Is(err, ErrFieldMismatch):
+		return "field_mismatch"
+	case errors.Is(err, ErrAggregation):
+		return "aggregation"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,156 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"cloud.google.com/go/datastore/apiv1/datastorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      error
+		class     string
+		retryable bool
+	}{
+		{name: "no such entity", err: datastore.ErrNoSuchEntity, kind: ErrNotFound, class: "not_found"},
+		{name: "concurrent transaction", err: datastore.ErrConcurrentTransaction, kind: ErrContention, class: "contention", retryable: true},
+		{name: "aborted", err: status.Error(codes.Aborted, "contention"), kind: ErrContention, class: "contention", retryable: true},
+		{name: "already exists", err: status.Error(codes.AlreadyExists, "exists"), kind: ErrConflict, class: "already_exists"},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "down"), kind: ErrUnavailable, class: "unavailable", retryable: true},
+		{name: "context deadline", err: context.DeadlineExceeded, kind: ErrDeadline, class: "deadline"},
+		{name: "argument", err: invalid("key", "is nil"), kind: ErrInvalidArgument, class: "invalid_argument"},
+		{name: "null aggregation", err: ErrNullAggregation, kind: ErrAggregation, class: "aggregation"},
+		{name: "unexpected value", err: ErrUnexpectedValue, kind: ErrAggregation, class: "aggregation"},
+		{
+			name:      "multi error all retryable",
+			err:       datastore.MultiError{nil, status.Error(codes.Aborted, "contention")},
+			class:     "contention",
+			retryable: true,
+		},
+		{
+			name:  "multi error mixed",
+			err:   datastore.MultiError{status.Error(codes.Aborted, "contention"), datastore.ErrNoSuchEntity},
+			class: "mixed",
+		},
+		{
+			name:  "multi error all missing",
+			err:   datastore.MultiError{datastore.ErrNoSuchEntity, nil, datastore.ErrNoSuchEntity},
+			kind:  ErrNotFound,
+			class: "not_found",
+		},
+		{
+			name:      "batch error all unavailable",
+			err:       &BatchError{MultiError: datastore.MultiError{status.Error(codes.Unavailable, "down"), nil}},
+			kind:      ErrUnavailable,
+			class:     "unavailable",
+			retryable: true,
+		},
+		{
+			name:      "wrapped batch error",
+			err:       fmt.Errorf("read: %w", &BatchError{MultiError: datastore.MultiError{nil, datastore.ErrConcurrentTransaction}}),
+			kind:      ErrContention,
+			class:     "contention",
+			retryable: true,
+		},
+		{
+			name:  "batch error mixed",
+			err:   &BatchError{MultiError: datastore.MultiError{status.Error(codes.Unavailable, "down"), datastore.ErrNoSuchEntity}},
+			kind:  ErrUnavailable,
+			class: "mixed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify(tt.err)
+
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Fatalf("Classify(%v) = %v, want %v", tt.err, err, tt.kind)
+			}
+
+			if got := ErrorClass(tt.err); got != tt.class {
+				t.Fatalf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.class)
+			}
+
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestClassifyChunkedFailure(t *testing.T) {
+	err := inBatches(context.Background(), 5, 2, func(_ context.Context, start, _ int) error {
+		if start == 2 {
+			return Classify(status.Error(codes.Unavailable, "down"))
+		}
+
+		return nil
+	})
+
+	var me datastore.MultiError
+	if !errors.As(Classify(err), &me) || len(me) != 5 || me[2] == nil || me[0] != nil {
+		t.Fatalf("Classify(%v) lost per-index errors", err)
+	}
+
+	if !errors.Is(err, ErrUnavailable) || !IsRetryable(err) || ErrorClass(err) != "unavailable" {
+		t.Fatalf("chunked failure %v: Is = %v, IsRetryable = %v, ErrorClass = %q",
+			err, errors.Is(err, ErrUnavailable), IsRetryable(err), ErrorClass(err))
+	}
+}
+
+func TestAggregationResultErrors(t *testing.T) {
+	result := &AggregationResult{values: map[string]*datastorepb.Value{
+		"count": {ValueType: &datastorepb.Value_IntegerValue{IntegerValue: 3}},
+		"avg":   {ValueType: &datastorepb.Value_NullValue{NullValue: structpb.NullValue_NULL_VALUE}},
+		"text":  {ValueType: &datastorepb.Value_StringValue{StringValue: "x"}},
+	}}
+
+	tests := []struct {
+		name string
+		read func() error
+		want error
+	}{
+		{name: "int", read: func() error { _, err := result.Int("count"); return err }},
+		{name: "float from int", read: func() error { _, err := result.Float("count"); return err }},
+		{name: "null", read: func() error { _, err := result.Float("avg"); return err }, want: ErrNullAggregation},
+		{name: "unexpected float", read: func() error { _, err := result.Float("text"); return err }, want: ErrUnexpectedValue},
+		{name: "unexpected int", read: func() error { _, err := result.Int("text"); return err }, want: ErrUnexpectedValue},
+		{name: "unknown alias", read: func() error { _, err := result.Int("sum"); return err }, want: ErrInvalidArgument},
+		{
+			name: "missing from result",
+			read: func() error { _, err := aggregateValue(datastore.AggregationResult{}, "count"); return err },
+			want: ErrMissingResult,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.read()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if !result.IsNull("avg") || result.IsNull("count") {
+		t.Fatal("IsNull() did not distinguish null results")
+	}
+}
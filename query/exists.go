@@ -8,7 +8,10 @@ import (
 	"google.golang.org/api/iterator"
 )
 
-func Exists(ctx context.Context, client Client, key *datastore.Key) (bool, error) {
+func Exists(ctx context.Context, client Client, key *datastore.Key) (out bool, err error) {
+	ctx, span := start(ctx, client, "Exists", keyAttributes(key)...)
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresClient(client); err != nil {
 		return false, err
 	}
@@ -19,20 +22,23 @@ func Exists(ctx context.Context, client Client, key *datastore.Key) (bool, error
 
 	var result datastore.Entity
 
-	err := client.Client().Get(ctx, key, &result)
+	err = client.Client().Get(ctx, key, &result)
 
 	if err == datastore.ErrNoSuchEntity {
 		return false, nil
 	}
 
 	if err != nil {
-		return false, err
+		return false, Classify(err)
 	}
 
 	return true, nil
 }
 
-func ExistsTxn(txn Transaction, key *datastore.Key) (bool, error) {
+func ExistsTxn(txn Transaction, key *datastore.Key) (out bool, err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "ExistsTxn", keyAttributes(key)...)
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return false, err
 	}
@@ -43,20 +49,23 @@ func ExistsTxn(txn Transaction, key *datastore.Key) (bool, error) {
 
 	var result datastore.Entity
 
-	err := txn.Txn().Get(key, &result)
+	err = txn.Txn().Get(key, &result)
 
 	if err == datastore.ErrNoSuchEntity {
 		return false, nil
 	}
 
 	if err != nil {
-		return false, err
+		return false, Classify(err)
 	}
 
 	return true, nil
 }
 
-func ExistsForQuery(ctx context.Context, client Client, query *datastore.Query) (bool, error) {
+func ExistsForQuery(ctx context.Context, client Client, query *datastore.Query) (out bool, err error) {
+	ctx, span := start(ctx, client, "ExistsForQuery")
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresClient(client); err != nil {
 		return false, err
 	}
@@ -67,20 +76,23 @@ func ExistsForQuery(ctx context.Context, client Client, query *datastore.Query)
 
 	it := client.Client().Run(ctx, query.KeysOnly().Limit(1))
 
-	_, err := it.Next(nil)
+	_, err = it.Next(nil)
 
 	if errors.Is(err, iterator.Done) {
 		return false, nil
 	}
 
 	if err != nil {
-		return false, err
+		return false, Classify(err)
 	}
 
 	return true, nil
 }
 
-func ExistsForQueryTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (bool, error) {
+func ExistsForQueryTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (out bool, err error) {
+	ctx, span := startTxn(ctx, txn, client, "ExistsForQueryTxn")
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return false, err
 	}
@@ -91,14 +103,14 @@ func ExistsForQueryTxn(ctx context.Context, txn Transaction, client Client, quer
 
 	it := client.Client().Run(ctx, query.KeysOnly().Limit(1).Transaction(txn.Txn()))
 
-	_, err := it.Next(nil)
+	_, err = it.Next(nil)
 
 	if errors.Is(err, iterator.Done) {
 		return false, nil
 	}
 
 	if err != nil {
-		return false, err
+		return false, Classify(err)
 	}
 
 	return true, nil
@@ -0,0 +1,171 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"cloud.google.com/go/datastore/apiv1/datastorepb"
+)
+
+type ExplainMode int
+
+const (
+	ExplainModePlan ExplainMode = iota
+	ExplainModeAnalyze
+)
+
+func (m ExplainMode) String() string {
+	if m == ExplainModeAnalyze {
+		return "analyze"
+	}
+
+	return "plan"
+}
+
+type Explanation struct {
+	Mode              ExplainMode
+	IndexesUsed       []map[string]any
+	ResultsReturned   int64
+	ReadOperations    int64
+	ExecutionDuration time.Duration
+	DebugStats        map[string]any
+	Elapsed           time.Duration
+}
+
+func Explain(ctx context.Context, client Client, query *datastore.Query) (out *Explanation, err error) {
+	ctx, span := start(ctx, client, "Explain")
+	defer func() { finish(span, err, nil) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return nil, err
+	}
+
+	var dst []datastore.PropertyList
+
+	started := time.Now()
+
+	res, err := client.Client().GetAllWithOptions(ctx, query, &dst, datastore.ExplainOptions{})
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	return explanation(ExplainModePlan, res.ExplainMetrics, time.Since(started)), nil
+}
+
+func ExplainAnalyze[E any](ctx context.Context, client Client, query *datastore.Query) (out []*E, _ *Explanation, err error) {
+	ctx, span := start(ctx, client, "ExplainAnalyze")
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, nil, err
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]datastore.PropertyList, 0, defaultQueryAllocationSize)
+
+	started := time.Now()
+
+	res, err := client.Client().GetAllWithOptions(ctx, query, &rows, datastore.ExplainOptions{Analyze: true})
+	if err != nil {
+		return nil, nil, Classify(err)
+	}
+
+	elapsed := time.Since(started)
+
+	entities := make([]*E, len(rows))
+
+	for i, props := range rows {
+		entities[i] = new(E)
+
+		var key *datastore.Key
+		if i < len(res.Keys) {
+			key = res.Keys[i]
+		}
+
+		if err := HandleFieldMismatch(ctx, key, datastore.LoadStruct(entities[i], props)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return entities, explanation(ExplainModeAnalyze, res.ExplainMetrics, elapsed), nil
+}
+
+func ExplainCount(ctx context.Context, client Client, query *datastore.Query) (out *Explanation, err error) {
+	ctx, span := start(ctx, client, "ExplainCount")
+	defer func() { finish(span, err, nil) }()
+
+	_, out, err = explainCount(ctx, client, query, ExplainModePlan)
+
+	return out, err
+}
+
+func ExplainAnalyzeCount(ctx context.Context, client Client, query *datastore.Query) (out int64, _ *Explanation, err error) {
+	ctx, span := start(ctx, client, "ExplainAnalyzeCount")
+	defer func() { finish(span, err, out) }()
+
+	return explainCount(ctx, client, query, ExplainModeAnalyze)
+}
+
+func explainCount(ctx context.Context, client Client, query *datastore.Query, mode ExplainMode) (int64, *Explanation, error) {
+	if err := requiresClient(client); err != nil {
+		return 0, nil, err
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return 0, nil, err
+	}
+
+	started := time.Now()
+
+	res, err := client.Client().RunAggregationQueryWithOptions(ctx, query.NewAggregationQuery().WithCount("count"), datastore.ExplainOptions{Analyze: mode == ExplainModeAnalyze})
+	if err != nil {
+		return 0, nil, Classify(err)
+	}
+
+	var count int64
+
+	if v, ok := res.Result["count"].(*datastorepb.Value); ok {
+		count = v.GetIntegerValue()
+	}
+
+	return count, explanation(mode, res.ExplainMetrics, time.Since(started)), nil
+}
+
+func explanation(mode ExplainMode, metrics *datastore.ExplainMetrics, elapsed time.Duration) *Explanation {
+	e := &Explanation{Mode: mode, Elapsed: elapsed}
+
+	if metrics == nil {
+		return e
+	}
+
+	if p := metrics.PlanSummary; p != nil {
+		for _, idx := range p.IndexesUsed {
+			if idx != nil {
+				e.IndexesUsed = append(e.IndexesUsed, *idx)
+			}
+		}
+	}
+
+	if s := metrics.ExecutionStats; s != nil {
+		e.ResultsReturned = s.ResultsReturned
+		e.ReadOperations = s.ReadOperations
+
+		if s.ExecutionDuration != nil {
+			e.ExecutionDuration = *s.ExecutionDuration
+		}
+
+		if s.DebugStats != nil {
+			e.DebugStats = *s.DebugStats
+		}
+	}
+
+	return e
+}
@@ -1,3 +1,7 @@
 package query
 
 type Generator[T any] func() *T
+
+func newEntity[T any]() *T {
+	return new(T)
+}
@@ -1,8 +1,6 @@
 package query
 
 import (
-	"errors"
-	"fmt"
 	"reflect"
 
 	"cloud.google.com/go/datastore"
@@ -10,11 +8,11 @@ import (
 
 func requiresClient(client Client) error {
 	if client == nil {
-		return errors.New("client cannot be nil")
+		return invalid("client", "cannot be nil")
 	}
 
 	if client.Client() == nil {
-		return errors.New("client datastore cannot be nil")
+		return invalid("client datastore", "cannot be nil")
 	}
 
 	return nil
@@ -22,7 +20,7 @@ func requiresClient(client Client) error {
 
 func requiresKey(key *datastore.Key) error {
 	if key == nil {
-		return errors.New("key cannot be nil")
+		return invalid("key", "cannot be nil")
 	}
 
 	return nil
@@ -30,7 +28,7 @@ func requiresKey(key *datastore.Key) error {
 
 func requiresKeys(keys []*datastore.Key) error {
 	if keys == nil {
-		return errors.New("keys cannot be nil")
+		return invalid("keys", "cannot be nil")
 	}
 
 	for _, k := range keys {
@@ -44,21 +42,25 @@ func requiresKeys(keys []*datastore.Key) error {
 
 func requiresEntity(entity any) error {
 	if entity == nil {
-		return errors.New("entity is nil")
+		return invalid("entity", "is nil")
 	}
 
 	v := reflect.ValueOf(entity)
 
 	if v.Kind() != reflect.Pointer {
-		return fmt.Errorf("entity must be a pointer to a struct, got %T", entity)
+		return invalid("entity", "must be a pointer to a struct, got %T", entity)
 	}
 
 	if v.IsNil() {
-		return errors.New("entity pointer is nil")
+		return invalid("entity pointer", "is nil")
+	}
+
+	if _, ok := entity.(datastore.PropertyLoadSaver); ok {
+		return nil
 	}
 
 	if v.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("entity must point to a struct, got pointer to %s", v.Elem().Kind())
+		return invalid("entity", "must point to a struct, got pointer to %s", v.Elem().Kind())
 	}
 
 	return nil
@@ -66,7 +68,7 @@ func requiresEntity(entity any) error {
 
 func requiresEntities[E any](entities []*E) error {
 	if entities == nil {
-		return errors.New("entities cannot be nil")
+		return invalid("entities", "cannot be nil")
 	}
 
 	for _, e := range entities {
@@ -80,7 +82,7 @@ func requiresEntities[E any](entities []*E) error {
 
 func requiresEqualLength[E any](keys []*datastore.Key, entities []*E) error {
 	if len(keys) != len(entities) {
-		return errors.New("keys and entities must have the same length")
+		return invalid("keys and entities", "must have the same length")
 	}
 
 	return nil
@@ -88,11 +90,11 @@ func requiresEqualLength[E any](keys []*datastore.Key, entities []*E) error {
 
 func requiresTransaction(txn Transaction) error {
 	if txn == nil {
-		return errors.New("transaction cannot be nil")
+		return invalid("transaction", "cannot be nil")
 	}
 
 	if txn.Txn() == nil {
-		return errors.New("transaction datastore cannot be nil")
+		return invalid("transaction datastore", "cannot be nil")
 	}
 
 	return nil
@@ -100,7 +102,7 @@ func requiresTransaction(txn Transaction) error {
 
 func requiresQuery(query *datastore.Query) error {
 	if query == nil {
-		return errors.New("query cannot be nil")
+		return invalid("query", "cannot be nil")
 	}
 
 	return nil
@@ -108,7 +110,7 @@ func requiresQuery(query *datastore.Query) error {
 
 func requiresField(field string) error {
 	if field == "" {
-		return errors.New("field cannot be empty")
+		return invalid("field", "cannot be empty")
 	}
 
 	return nil
@@ -116,7 +118,7 @@ func requiresField(field string) error {
 
 func requiresFields(fields []string) error {
 	if fields == nil {
-		return errors.New("fields cannot be nil")
+		return invalid("fields", "cannot be nil")
 	}
 
 	for _, f := range fields {
@@ -130,7 +132,7 @@ func requiresFields(fields []string) error {
 
 func requiresOneField(fields ...[]string) error {
 	if len(fields) == 0 {
-		return errors.New("at least one field slice must be provided")
+		return invalid("fields", "must contain at least one field slice")
 	}
 
 	found := false
@@ -144,7 +146,7 @@ func requiresOneField(fields ...[]string) error {
 	}
 
 	if !found {
-		return errors.New("at least one field must be provided")
+		return invalid("fields", "must contain at least one field")
 	}
 
 	return nil
@@ -0,0 +1,344 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	"google.golang.org/api/iterator"
+)
+
+type Iterator[E any] struct {
+	next   func() (*datastore.Key, *E, error)
+	cursor func() (datastore.Cursor, error)
+	err    error
+	done   bool
+	span   *telemetry.Span
+	count  int
+	onDone []func(err error)
+}
+
+func NewIterator[E any](next func() (*datastore.Key, *E, error), cursor func() (datastore.Cursor, error)) *Iterator[E] {
+	return &Iterator[E]{
+		next:   next,
+		cursor: cursor,
+	}
+}
+
+func FailedIterator[E any](err error) *Iterator[E] {
+	return &Iterator[E]{
+		err:  err,
+		done: true,
+	}
+}
+
+func (it *Iterator[E]) observe(span *telemetry.Span) *Iterator[E] {
+	it.span = span
+
+	if it.done {
+		it.finish()
+	}
+
+	return it
+}
+
+func (it *Iterator[E]) OnDone(fn func(err error)) *Iterator[E] {
+	if it.done {
+		fn(it.err)
+
+		return it
+	}
+
+	it.onDone = append(it.onDone, fn)
+
+	return it
+}
+
+func (it *Iterator[E]) finish() {
+	if it.span != nil {
+		finish(it.span, it.err, it.count)
+	}
+
+	onDone := it.onDone
+	it.onDone = nil
+
+	for _, fn := range onDone {
+		fn(it.err)
+	}
+}
+
+func (it *Iterator[E]) advance() (*datastore.Key, *E, bool) {
+	if it.done {
+		return nil, nil, false
+	}
+
+	k, e, err := it.next()
+	if errors.Is(err, iterator.Done) {
+		it.done = true
+		it.finish()
+
+		return nil, nil, false
+	}
+
+	if err != nil {
+		it.err = Classify(err)
+		it.done = true
+		it.finish()
+
+		return nil, nil, false
+	}
+
+	it.count++
+
+	return k, e, true
+}
+
+func (it *Iterator[E]) All() iter.Seq2[*E, error] {
+	return func(yield func(*E, error) bool) {
+		for {
+			_, e, ok := it.advance()
+			if !ok {
+				if it.err != nil {
+					yield(nil, it.err)
+				}
+
+				return
+			}
+
+			if !yield(e, nil) {
+				it.finish()
+
+				return
+			}
+		}
+	}
+}
+
+// Keyed yields each entity with its key. Unlike All and Keys it has no slot
+// for an error, so the sequence simply ends when a read fails; check Err
+// after the loop to tell a failure from the end of the results.
+func (it *Iterator[E]) Keyed() iter.Seq2[*datastore.Key, *E] {
+	return func(yield func(*datastore.Key, *E) bool) {
+		for {
+			k, e, ok := it.advance()
+			if !ok {
+				return
+			}
+
+			if !yield(k, e) {
+				it.finish()
+
+				return
+			}
+		}
+	}
+}
+
+func (it *Iterator[E]) Keys() iter.Seq2[*datastore.Key, error] {
+	return func(yield func(*datastore.Key, error) bool) {
+		for {
+			k, _, ok := it.advance()
+			if !ok {
+				if it.err != nil {
+					yield(nil, it.err)
+				}
+
+				return
+			}
+
+			if !yield(k, nil) {
+				it.finish()
+
+				return
+			}
+		}
+	}
+}
+
+func (it *Iterator[E]) Err() error {
+	return it.err
+}
+
+func (it *Iterator[E]) Cursor() (*datastore.Cursor, error) {
+	if it.cursor == nil {
+		return nil, it.err
+	}
+
+	c, err := it.cursor()
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	return &c, nil
+}
+
+func (it *Iterator[E]) Inspect(fn func(key *datastore.Key, entity *E) error) *Iterator[E] {
+	if it.done || it.next == nil || fn == nil {
+		return it
+	}
+
+	next := it.next
+
+	it.next = func() (*datastore.Key, *E, error) {
+		k, e, err := next()
+		if err != nil {
+			return k, e, err
+		}
+
+		if err := fn(k, e); err != nil {
+			return nil, nil, err
+		}
+
+		return k, e, nil
+	}
+
+	return it
+}
+
+func (it *Iterator[E]) collect() ([]*E, *datastore.Cursor, error) {
+	entities := make([]*E, 0, defaultQueryAllocationSize)
+
+	for e, err := range it.All() {
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entities = append(entities, e)
+	}
+
+	c, err := it.Cursor()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entities, c, nil
+}
+
+func (it *Iterator[E]) collectKeys() ([]*datastore.Key, *datastore.Cursor, error) {
+	keys := make([]*datastore.Key, 0, defaultQueryAllocationSize)
+
+	for k, err := range it.Keys() {
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	c, err := it.Cursor()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return keys, c, nil
+}
+
+func entityIterator[E any](ctx context.Context, it *datastore.Iterator, generate Generator[E]) *Iterator[E] {
+	return NewIterator(func() (*datastore.Key, *E, error) {
+		e := generate()
+
+		k, err := it.Next(e)
+		if err := HandleFieldMismatch(ctx, k, err); err != nil {
+			return nil, nil, err
+		}
+
+		return k, e, nil
+	}, it.Cursor)
+}
+
+func keyIterator(it *datastore.Iterator) *Iterator[datastore.Key] {
+	return NewIterator(func() (*datastore.Key, *datastore.Key, error) {
+		k, err := it.Next(nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return k, k, nil
+	}, it.Cursor)
+}
+
+func Iterate[E any](ctx context.Context, client Client, query *datastore.Query) *Iterator[E] {
+	ctx, span := start(ctx, client, "Iterate")
+
+	if err := requiresClient(client); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	return entityIterator(ctx, client.Client().Run(ctx, query), newEntity[E]).observe(span)
+}
+
+func IterateTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query) *Iterator[E] {
+	ctx, span := startTxn(ctx, txn, client, "IterateTxn")
+
+	if err := requiresTransaction(txn); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	return entityIterator(ctx, client.Client().Run(ctx, query.Transaction(txn.Txn())), newEntity[E]).observe(span)
+}
+
+func IterateProjection[E any](ctx context.Context, client Client, query *datastore.Query, generate Generator[E], fields ...string) *Iterator[E] {
+	ctx, span := start(ctx, client, "IterateProjection")
+
+	if err := requiresClient(client); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	return entityIterator(ctx, client.Client().Run(ctx, query.Project(fields...)), generate).observe(span)
+}
+
+func IterateProjectionTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query, generate Generator[E], fields ...string) *Iterator[E] {
+	ctx, span := startTxn(ctx, txn, client, "IterateProjectionTxn")
+
+	if err := requiresTransaction(txn); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return FailedIterator[E](err).observe(span)
+	}
+
+	return entityIterator(ctx, client.Client().Run(ctx, query.Project(fields...).Transaction(txn.Txn())), generate).observe(span)
+}
+
+func IterateKeys(ctx context.Context, client Client, query *datastore.Query) *Iterator[datastore.Key] {
+	ctx, span := start(ctx, client, "IterateKeys")
+
+	if err := requiresClient(client); err != nil {
+		return FailedIterator[datastore.Key](err).observe(span)
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return FailedIterator[datastore.Key](err).observe(span)
+	}
+
+	return keyIterator(client.Client().Run(ctx, query.KeysOnly())).observe(span)
+}
+
+func IterateKeysTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) *Iterator[datastore.Key] {
+	ctx, span := startTxn(ctx, txn, client, "IterateKeysTxn")
+
+	if err := requiresTransaction(txn); err != nil {
+		return FailedIterator[datastore.Key](err).observe(span)
+	}
+
+	if err := requiresQuery(query); err != nil {
+		return FailedIterator[datastore.Key](err).observe(span)
+	}
+
+	return keyIterator(client.Client().Run(ctx, query.KeysOnly().Transaction(txn.Txn()))).observe(span)
+}
@@ -3,9 +3,19 @@ package query
 import (
 	"fmt"
 
+	"cloud.google.com/go/datastore"
 	"cloud.google.com/go/datastore/apiv1/datastorepb"
 )
 
+func aggregateValue(r datastore.AggregationResult, alias string) (*datastorepb.Value, error) {
+	v, ok := r[alias].(*datastorepb.Value)
+	if !ok {
+		return nil, fmt.Errorf("aggregation %q: %w", alias, ErrMissingResult)
+	}
+
+	return v, nil
+}
+
 func numericValue(v *datastorepb.Value) (float64, error) {
 	switch value := v.GetValueType().(type) {
 	case *datastorepb.Value_IntegerValue:
@@ -13,6 +23,6 @@ func numericValue(v *datastorepb.Value) (float64, error) {
 	case *datastorepb.Value_DoubleValue:
 		return value.DoubleValue, nil
 	default:
-		return 0, fmt.Errorf("unexpected value type %T for numeric aggregation", value)
+		return 0, fmt.Errorf("%w %T for numeric aggregation", ErrUnexpectedValue, value)
 	}
 }
@@ -0,0 +1,153 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"cloud.google.com/go/datastore"
+)
+
+type MismatchPolicy int
+
+const (
+	IgnoreMismatches MismatchPolicy = iota
+	CollectMismatches
+	FailOnMismatch
+)
+
+func (p MismatchPolicy) String() string {
+	switch p {
+	case CollectMismatches:
+		return "collect"
+	case FailOnMismatch:
+		return "fail"
+	default:
+		return "ignore"
+	}
+}
+
+type FieldMismatch struct {
+	Key        *datastore.Key
+	StructType reflect.Type
+	Field      string
+	Reason     string
+	err        error
+}
+
+func (m *FieldMismatch) Error() string {
+	if m.Key == nil {
+		return fmt.Sprintf("cannot load field %q into %v: %s", m.Field, m.StructType, m.Reason)
+	}
+
+	return fmt.Sprintf("cannot load field %q of %v into %v: %s", m.Field, m.Key, m.StructType, m.Reason)
+}
+
+func (m *FieldMismatch) Unwrap() error {
+	return m.err
+}
+
+type MismatchHandler func(ctx context.Context, m *FieldMismatch)
+
+type Mismatches struct {
+	mu    sync.Mutex
+	items []*FieldMismatch
+}
+
+func (m *Mismatches) All() []*FieldMismatch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.items)
+}
+
+func (m *Mismatches) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.items)
+}
+
+func (m *Mismatches) Drain() []*FieldMismatch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := m.items
+	m.items = nil
+
+	return items
+}
+
+func (m *Mismatches) add(fm *FieldMismatch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = append(m.items, fm)
+}
+
+type mismatchContextKey struct{}
+
+type mismatchConfig struct {
+	policy    MismatchPolicy
+	handler   MismatchHandler
+	collected *Mismatches
+}
+
+func WithMismatchPolicy(ctx context.Context, policy MismatchPolicy, handler MismatchHandler) (context.Context, *Mismatches) {
+	collected := &Mismatches{}
+
+	return WithMismatchCollector(ctx, policy, handler, collected), collected
+}
+
+func WithMismatchCollector(ctx context.Context, policy MismatchPolicy, handler MismatchHandler, collected *Mismatches) context.Context {
+	if collected == nil {
+		collected = &Mismatches{}
+	}
+
+	return context.WithValue(ctx, mismatchContextKey{}, &mismatchConfig{policy: policy, handler: handler, collected: collected})
+}
+
+func MismatchPolicyFromContext(ctx context.Context) (MismatchPolicy, bool) {
+	cfg, ok := ctx.Value(mismatchContextKey{}).(*mismatchConfig)
+	if !ok {
+		return IgnoreMismatches, false
+	}
+
+	return cfg.policy, true
+}
+
+func HandleFieldMismatch(ctx context.Context, key *datastore.Key, err error) error {
+	var efm *datastore.ErrFieldMismatch
+
+	if !errors.As(err, &efm) {
+		return err
+	}
+
+	cfg, ok := ctx.Value(mismatchContextKey{}).(*mismatchConfig)
+	if !ok || cfg.policy == IgnoreMismatches {
+		return nil
+	}
+
+	fm := &FieldMismatch{
+		Key:        key,
+		StructType: efm.StructType,
+		Field:      efm.FieldName,
+		Reason:     efm.Reason,
+		err:        err,
+	}
+
+	cfg.collected.add(fm)
+
+	if cfg.handler != nil {
+		cfg.handler(ctx, fm)
+	}
+
+	if cfg.policy == FailOnMismatch {
+		return &Error{Kind: ErrFieldMismatch, Err: fm}
+	}
+
+	return nil
+}
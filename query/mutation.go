@@ -0,0 +1,80 @@
+package query
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+)
+
+type mutationFunc func(key *datastore.Key, src any) *datastore.Mutation
+
+func mutations[E any](mutation mutationFunc, keys []*datastore.Key, entities []*E) []*datastore.Mutation {
+	muts := make([]*datastore.Mutation, len(keys))
+
+	for i := range keys {
+		muts[i] = mutation(keys[i], entities[i])
+	}
+
+	return muts
+}
+
+func mutate(ctx context.Context, client Client, muts ...*datastore.Mutation) ([]*datastore.Key, error) {
+	keys, err := client.Client().Mutate(ctx, muts...)
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	return keys, nil
+}
+
+func mutateMulti[E any](ctx context.Context, client Client, mutation mutationFunc, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error) {
+	result := make([]*datastore.Key, len(keys))
+
+	err := inBatches(ctx, len(keys), maxMutationBatchSize, func(ctx context.Context, start, end int) error {
+		k, err := mutate(ctx, client, mutations(mutation, keys[start:end], entities[start:end])...)
+		if err != nil {
+			return err
+		}
+
+		copy(result[start:end], k)
+
+		return nil
+	})
+
+	return result, err
+}
+
+func mutateOne(ctx context.Context, client Client, mut *datastore.Mutation) (*datastore.Key, error) {
+	keys, err := mutate(ctx, client, mut)
+	if err != nil {
+		return nil, single(err)
+	}
+
+	return keys[0], nil
+}
+
+func mutateTxn(txn Transaction, muts ...*datastore.Mutation) ([]*datastore.PendingKey, error) {
+	pks, err := txn.Txn().Mutate(muts...)
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	return pks, nil
+}
+
+func mutateOneTxn(txn Transaction, mut *datastore.Mutation) (*datastore.PendingKey, error) {
+	pks, err := mutateTxn(txn, mut)
+	if err != nil {
+		return nil, single(err)
+	}
+
+	return pks[0], nil
+}
+
+func single(err error) error {
+	if me, ok := multiError(err); ok && len(me) == 1 {
+		return me[0]
+	}
+
+	return err
+}
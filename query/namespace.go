@@ -0,0 +1,31 @@
+package query
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+)
+
+const namespaceKind = "__namespace__"
+
+func Namespaces(ctx context.Context, client Client) (out []string, err error) {
+	ctx, span := start(ctx, client, "Namespaces")
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	keys, err := client.Client().GetAll(ctx, datastore.NewQuery(namespaceKind).KeysOnly(), nil)
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	namespaces := make([]string, len(keys))
+
+	for i, k := range keys {
+		namespaces[i] = k.Name
+	}
+
+	return namespaces, nil
+}
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/datastore"
+)
+
+type ReadState int
+
+const (
+	ReadFound ReadState = iota
+	ReadMissing
+	ReadFieldMismatch
+	ReadFailed
+)
+
+func (s ReadState) String() string {
+	switch s {
+	case ReadFound:
+		return "found"
+	case ReadMissing:
+		return "missing"
+	case ReadFieldMismatch:
+		return "field-mismatch"
+	case ReadFailed:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+type ReadStatus struct {
+	Key   *datastore.Key
+	State ReadState
+	Err   error
+}
+
+func (s ReadStatus) Loaded() bool {
+	return s.State == ReadFound || s.State == ReadFieldMismatch
+}
+
+func ReadMultiPartial[E any](ctx context.Context, client Client, keys []*datastore.Key, entities []*E) (out []ReadStatus, err error) {
+	ctx, span := start(ctx, client, "ReadMultiPartial", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
+	return readStatuses(keys, ReadMulti(ctx, client, keys, entities))
+}
+
+func ReadMultiPartialTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E) (out []ReadStatus, err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "ReadMultiPartialTxn", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
+	return readStatuses(keys, ReadMultiTxn(txn, keys, entities))
+}
+
+func ReadFailures(statuses []ReadStatus) error {
+	var errs datastore.MultiError
+
+	for i, s := range statuses {
+		if s.State != ReadFailed {
+			continue
+		}
+
+		if errs == nil {
+			errs = make(datastore.MultiError, len(statuses))
+		}
+
+		errs[i] = s.Err
+	}
+
+	if errs == nil {
+		return nil
+	}
+
+	return &BatchError{MultiError: errs}
+}
+
+func readStatuses(keys []*datastore.Key, err error) ([]ReadStatus, error) {
+	statuses := make([]ReadStatus, len(keys))
+
+	for i, k := range keys {
+		statuses[i] = ReadStatus{Key: k, State: ReadFound}
+	}
+
+	if err == nil {
+		return statuses, nil
+	}
+
+	var me datastore.MultiError
+
+	if !errors.As(err, &me) || len(me) != len(keys) {
+		return nil, err
+	}
+
+	for i, e := range me {
+		statuses[i].State = readState(e)
+		statuses[i].Err = e
+	}
+
+	return statuses, nil
+}
+
+func readState(err error) ReadState {
+	if err == nil {
+		return ReadFound
+	}
+
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return ReadMissing
+	}
+
+	var efm *datastore.ErrFieldMismatch
+
+	if errors.As(err, &efm) {
+		return ReadFieldMismatch
+	}
+
+	return ReadFailed
+}
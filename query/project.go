@@ -2,99 +2,28 @@ package query
 
 import (
 	"context"
-	"errors"
 
 	"cloud.google.com/go/datastore"
-	"google.golang.org/api/iterator"
 )
 
-func Project[E any](ctx context.Context, client Client, query *datastore.Query, generate Generator[E], fields ...string) ([]*E, *datastore.Cursor, error) {
-	if err := requiresClient(client); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
-	}
-
-	it := client.Client().Run(ctx, query.Project(fields...))
-	entities := make([]*E, 0, defaultQueryAllocationSize)
-
-	for {
-		e := generate()
-
-		_, err := it.Next(e)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-
-		var efm *datastore.ErrFieldMismatch
-
-		if errors.As(err, &efm) {
-			entities = append(entities, e)
+func Project[E any](ctx context.Context, client Client, query *datastore.Query, generate Generator[E], fields ...string) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := start(ctx, client, "Project")
+	defer func() { finishPage(span, err, out, next) }()
 
-			continue
-		}
-
-		if err != nil {
-			return nil, nil, err
-		}
-
-		entities = append(entities, e)
-	}
-
-	c, err := it.Cursor()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return entities, &c, nil
+	return IterateProjection(ctx, client, query, generate, fields...).collect()
 }
 
-func ProjectTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query, generate Generator[E], fields ...string) ([]*E, *datastore.Cursor, error) {
-	if err := requiresTransaction(txn); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
-	}
-
-	it := client.Client().Run(ctx, query.Project(fields...).Transaction(txn.Txn()))
-	entities := make([]*E, 0, defaultQueryAllocationSize)
-
-	for {
-		e := generate()
-
-		_, err := it.Next(e)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-
-		var efm *datastore.ErrFieldMismatch
-
-		if errors.As(err, &efm) {
-			entities = append(entities, e)
-
-			continue
-		}
+func ProjectTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query, generate Generator[E], fields ...string) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := startTxn(ctx, txn, client, "ProjectTxn")
+	defer func() { finishPage(span, err, out, next) }()
 
-		if err != nil {
-			return nil, nil, err
-		}
-
-		entities = append(entities, e)
-	}
-
-	c, err := it.Cursor()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return entities, &c, nil
+	return IterateProjectionTxn(ctx, txn, client, query, generate, fields...).collect()
 }
 
-func ProjectOne[E any](ctx context.Context, client Client, query *datastore.Query, generate Generator[E], fields ...string) (*E, error) {
+func ProjectOne[E any](ctx context.Context, client Client, query *datastore.Query, generate Generator[E], fields ...string) (out *E, err error) {
+	ctx, span := start(ctx, client, "ProjectOne")
+	defer func() { finish(span, err, out) }()
+
 	entities, _, err := Project(ctx, client, query, generate, fields...)
 	if err != nil {
 		return nil, err
@@ -107,7 +36,10 @@ func ProjectOne[E any](ctx context.Context, client Client, query *datastore.Quer
 	return entities[0], nil
 }
 
-func ProjectOneTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query, generate Generator[E], fields ...string) (*E, error) {
+func ProjectOneTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query, generate Generator[E], fields ...string) (out *E, err error) {
+	ctx, span := startTxn(ctx, txn, client, "ProjectOneTxn")
+	defer func() { finish(span, err, out) }()
+
 	entities, _, err := ProjectTxn(ctx, txn, client, query, generate, fields...)
 	if err != nil {
 		return nil, err
@@ -2,167 +2,60 @@ package query
 
 import (
 	"context"
-	"errors"
 
 	"cloud.google.com/go/datastore"
-	"google.golang.org/api/iterator"
 )
 
-func Query[E any](ctx context.Context, client Client, query *datastore.Query) ([]*E, *datastore.Cursor, error) {
-	if err := requiresClient(client); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
-	}
-
-	it := client.Client().Run(ctx, query)
-	entities := make([]*E, 0, defaultQueryAllocationSize)
-
-	for {
-		var e E
-
-		_, err := it.Next(&e)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
+func Query[E any](ctx context.Context, client Client, query *datastore.Query, opts ...ReadOption) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := start(ctx, client, "Query")
+	defer func() { finishPage(span, err, out, next) }()
 
-		var efm *datastore.ErrFieldMismatch
-
-		if errors.As(err, &efm) {
-			entities = append(entities, &e)
-
-			continue
-		}
-
-		if err != nil {
-			return nil, nil, err
-		}
-
-		entities = append(entities, &e)
-	}
-
-	c, err := it.Cursor()
+	txn, err := snapshot(ctx, client, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return entities, &c, nil
-}
-
-func QueryTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query) ([]*E, *datastore.Cursor, error) {
-	if err := requiresTransaction(txn); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
+	if txn != nil {
+		return IterateTxn[E](ctx, txn, client, query).collect()
 	}
 
-	query = query.Transaction(txn.Txn())
-
-	it := client.Client().Run(ctx, query)
-	entities := make([]*E, 0, defaultQueryAllocationSize)
-
-	for {
-		var e E
-
-		_, err := it.Next(&e)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-
-		var efm *datastore.ErrFieldMismatch
-
-		if errors.As(err, &efm) {
-			entities = append(entities, &e)
-
-			continue
-		}
-
-		if err != nil {
-			return nil, nil, err
-		}
-
-		entities = append(entities, &e)
-	}
-
-	c, err := it.Cursor()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return entities, &c, nil
+	return Iterate[E](ctx, client, query).collect()
 }
 
-func QueryKeys(ctx context.Context, client Client, query *datastore.Query) ([]*datastore.Key, *datastore.Cursor, error) {
-	if err := requiresClient(client); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
-	}
+func QueryTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := startTxn(ctx, txn, client, "QueryTxn")
+	defer func() { finishPage(span, err, out, next) }()
 
-	it := client.Client().Run(ctx, query.KeysOnly())
-	keys := make([]*datastore.Key, 0, defaultQueryAllocationSize)
-
-	for {
-		k, err := it.Next(nil)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-
-		if err != nil {
-			return nil, nil, err
-		}
+	return IterateTxn[E](ctx, txn, client, query).collect()
+}
 
-		keys = append(keys, k)
-	}
+func QueryKeys(ctx context.Context, client Client, query *datastore.Query, opts ...ReadOption) (out []*datastore.Key, next *datastore.Cursor, err error) {
+	ctx, span := start(ctx, client, "QueryKeys")
+	defer func() { finishPage(span, err, out, next) }()
 
-	c, err := it.Cursor()
+	txn, err := snapshot(ctx, client, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return keys, &c, nil
-}
-
-func QueryKeysTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) ([]*datastore.Key, *datastore.Cursor, error) {
-	if err := requiresTransaction(txn); err != nil {
-		return nil, nil, err
-	}
-
-	if err := requiresQuery(query); err != nil {
-		return nil, nil, err
+	if txn != nil {
+		return IterateKeysTxn(ctx, txn, client, query).collectKeys()
 	}
 
-	it := client.Client().Run(ctx, query.KeysOnly().Transaction(txn.Txn()))
-	keys := make([]*datastore.Key, 0, defaultQueryAllocationSize)
-
-	for {
-		k, err := it.Next(nil)
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-
-		if err != nil {
-			return nil, nil, err
-		}
+	return IterateKeys(ctx, client, query).collectKeys()
+}
 
-		keys = append(keys, k)
-	}
+func QueryKeysTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (out []*datastore.Key, next *datastore.Cursor, err error) {
+	ctx, span := startTxn(ctx, txn, client, "QueryKeysTxn")
+	defer func() { finishPage(span, err, out, next) }()
 
-	c, err := it.Cursor()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return keys, &c, nil
+	return IterateKeysTxn(ctx, txn, client, query).collectKeys()
 }
 
-func QueryOne[E any](ctx context.Context, client Client, query *datastore.Query) (*E, error) {
+func QueryOne[E any](ctx context.Context, client Client, query *datastore.Query) (out *E, err error) {
+	ctx, span := start(ctx, client, "QueryOne")
+	defer func() { finish(span, err, out) }()
+
 	entities, _, err := Query[E](ctx, client, query.Limit(1))
 	if err != nil {
 		return nil, err
@@ -175,7 +68,10 @@ func QueryOne[E any](ctx context.Context, client Client, query *datastore.Query)
 	return entities[0], nil
 }
 
-func QueryOneTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query) (*E, error) {
+func QueryOneTxn[E any](ctx context.Context, txn Transaction, client Client, query *datastore.Query) (out *E, err error) {
+	ctx, span := startTxn(ctx, txn, client, "QueryOneTxn")
+	defer func() { finish(span, err, out) }()
+
 	entities, _, err := QueryTxn[E](ctx, txn, client, query.Limit(1))
 	if err != nil {
 		return nil, err
@@ -188,7 +84,10 @@ func QueryOneTxn[E any](ctx context.Context, txn Transaction, client Client, que
 	return entities[0], nil
 }
 
-func QueryOneKey(ctx context.Context, client Client, query *datastore.Query) (*datastore.Key, error) {
+func QueryOneKey(ctx context.Context, client Client, query *datastore.Query) (out *datastore.Key, err error) {
+	ctx, span := start(ctx, client, "QueryOneKey")
+	defer func() { finish(span, err, out) }()
+
 	keys, _, err := QueryKeys(ctx, client, query.KeysOnly().Limit(1))
 	if err != nil {
 		return nil, err
@@ -201,7 +100,10 @@ func QueryOneKey(ctx context.Context, client Client, query *datastore.Query) (*d
 	return keys[0], nil
 }
 
-func QueryOneKeyTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (*datastore.Key, error) {
+func QueryOneKeyTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query) (out *datastore.Key, err error) {
+	ctx, span := startTxn(ctx, txn, client, "QueryOneKeyTxn")
+	defer func() { finish(span, err, out) }()
+
 	keys, _, err := QueryKeysTxn(ctx, txn, client, query.KeysOnly().Limit(1))
 	if err != nil {
 		return nil, err
@@ -6,7 +6,10 @@ import (
 	"cloud.google.com/go/datastore"
 )
 
-func Read[E any](ctx context.Context, client Client, key *datastore.Key, entity *E) error {
+func Read[E any](ctx context.Context, client Client, key *datastore.Key, entity *E, opts ...ReadOption) (err error) {
+	ctx, span := start(ctx, client, "Read", keyAttributes(key)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresClient(client); err != nil {
 		return err
 	}
@@ -19,10 +22,22 @@ func Read[E any](ctx context.Context, client Client, key *datastore.Key, entity
 		return err
 	}
 
-	return client.Client().Get(ctx, key, entity)
+	txn, err := snapshot(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
+	if txn != nil {
+		return Classify(txn.Txn().Get(key, entity))
+	}
+
+	return Classify(client.Client().Get(ctx, key, entity))
 }
 
-func ReadTxn[E any](txn Transaction, key *datastore.Key, entity *E) error {
+func ReadTxn[E any](txn Transaction, key *datastore.Key, entity *E) (err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "ReadTxn", keyAttributes(key)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return err
 	}
@@ -35,10 +50,13 @@ func ReadTxn[E any](txn Transaction, key *datastore.Key, entity *E) error {
 		return err
 	}
 
-	return txn.Txn().Get(key, entity)
+	return Classify(txn.Txn().Get(key, entity))
 }
 
-func ReadMulti[E any](ctx context.Context, client Client, keys []*datastore.Key, entities []*E) error {
+func ReadMulti[E any](ctx context.Context, client Client, keys []*datastore.Key, entities []*E, opts ...ReadOption) (err error) {
+	ctx, span := start(ctx, client, "ReadMulti", keyAttributes(keys...)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresClient(client); err != nil {
 		return err
 	}
@@ -55,10 +73,24 @@ func ReadMulti[E any](ctx context.Context, client Client, keys []*datastore.Key,
 		return err
 	}
 
-	return client.Client().GetMulti(ctx, keys, entities)
+	txn, err := snapshot(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
+	return inBatches(ctx, len(keys), maxLookupBatchSize, func(ctx context.Context, start, end int) error {
+		if txn != nil {
+			return Classify(txn.Txn().GetMulti(keys[start:end], entities[start:end]))
+		}
+
+		return Classify(client.Client().GetMulti(ctx, keys[start:end], entities[start:end]))
+	})
 }
 
-func ReadMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E) error {
+func ReadMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E) (err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "ReadMultiTxn", keyAttributes(keys...)...)
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return err
 	}
@@ -75,5 +107,5 @@ func ReadMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E)
 		return err
 	}
 
-	return txn.Txn().GetMulti(keys, entities)
+	return Classify(txn.Txn().GetMulti(keys, entities))
 }
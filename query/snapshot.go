@@ -0,0 +1,77 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	readTime time.Time
+}
+
+func newReadConfig(opts []ReadOption) *readConfig {
+	cfg := &readConfig{}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	return cfg
+}
+
+func WithReadTime(t time.Time) ReadOption {
+	return func(c *readConfig) {
+		c.readTime = t
+	}
+}
+
+func ReadOnlyOptions(opts ...ReadOption) []datastore.TransactionOption {
+	cfg := newReadConfig(opts)
+	txOpts := []datastore.TransactionOption{datastore.ReadOnly}
+
+	if !cfg.readTime.IsZero() {
+		txOpts = append(txOpts, datastore.WithReadTime(cfg.readTime))
+	}
+
+	return txOpts
+}
+
+type snapshotTxn struct {
+	ctx context.Context
+	tx  *datastore.Transaction
+}
+
+func (s *snapshotTxn) Txn() *datastore.Transaction {
+	return s.tx
+}
+
+func (s *snapshotTxn) Context() context.Context {
+	return s.ctx
+}
+
+// Client.WithReadOptions mutates the shared client and only covers lookups, so
+// snapshots begin a read-only transaction inline with their first read instead.
+func snapshot(ctx context.Context, client Client, opts []ReadOption) (Transaction, error) {
+	if newReadConfig(opts).readTime.IsZero() {
+		return nil, nil
+	}
+
+	return beginSnapshot(ctx, client, opts)
+}
+
+func beginSnapshot(ctx context.Context, client Client, opts []ReadOption) (Transaction, error) {
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	tx, err := client.Client().NewTransaction(ctx, append(ReadOnlyOptions(opts...), datastore.BeginLater)...)
+	if err != nil {
+		return nil, Classify(err)
+	}
+
+	return &snapshotTxn{ctx: ctx, tx: tx}, nil
+}
@@ -0,0 +1,103 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type counter struct {
+	N int
+}
+
+func TestSnapshotReads(t *testing.T) {
+	ctx := context.Background()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	key := datastore.NameKey("Counter", "a", nil)
+
+	if _, err := c.Client().Put(ctx, key, &counter{N: 1}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+	readTime := time.Now()
+	time.Sleep(time.Millisecond)
+
+	if _, err := c.Client().Put(ctx, key, &counter{N: 2}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	at := q.WithReadTime(readTime)
+	query := datastore.NewQuery("Counter")
+
+	tests := []struct {
+		name string
+		read func() (float64, error)
+	}{
+		{
+			name: "Read",
+			read: func() (float64, error) {
+				var e counter
+				err := q.Read(ctx, c, key, &e, at)
+
+				return float64(e.N), err
+			},
+		},
+		{
+			name: "ReadMulti",
+			read: func() (float64, error) {
+				entities := []*counter{{}}
+				err := q.ReadMulti(ctx, c, []*datastore.Key{key}, entities, at)
+
+				return float64(entities[0].N), err
+			},
+		},
+		{
+			name: "Query",
+			read: func() (float64, error) {
+				out, _, err := q.Query[counter](ctx, c, query, at)
+				if err != nil || len(out) != 1 {
+					return 0, err
+				}
+
+				return float64(out[0].N), nil
+			},
+		},
+		{
+			name: "SumForField",
+			read: func() (float64, error) {
+				return q.SumForField(ctx, c, query, "N", at)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			begins, rollbacks := c.Store().Calls("BeginTransaction"), c.Store().Calls("Rollback")
+
+			got, err := tt.read()
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+
+			if got != 1 {
+				t.Fatalf("%s() = %v, want the value at the read time", tt.name, got)
+			}
+
+			if n := c.Store().Calls("BeginTransaction") - begins; n != 0 {
+				t.Fatalf("%s() issued %d BeginTransaction calls, want 0", tt.name, n)
+			}
+
+			if n := c.Store().Calls("Rollback") - rollbacks; n != 0 {
+				t.Fatalf("%s() issued %d Rollback calls, want 0", tt.name, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package query
+
+import (
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+type Spec struct {
+	ancestor   *datastore.Key
+	namespace  string
+	filters    []datastore.EntityFilter
+	orders     []string
+	project    []string
+	distinctOn []string
+	limit      int
+	offset     int
+	cursor     string
+}
+
+type SpecValues struct {
+	Ancestor   *datastore.Key
+	Namespace  string
+	Filters    []datastore.EntityFilter
+	Orders     []string
+	Project    []string
+	DistinctOn []string
+	Limit      int
+	Offset     int
+	Cursor     string
+}
+
+func NewSpec() *Spec {
+	return &Spec{}
+}
+
+func (s *Spec) clone() *Spec {
+	if s == nil {
+		return &Spec{}
+	}
+
+	c := *s
+	c.filters = append([]datastore.EntityFilter(nil), s.filters...)
+	c.orders = append([]string(nil), s.orders...)
+	c.project = append([]string(nil), s.project...)
+	c.distinctOn = append([]string(nil), s.distinctOn...)
+
+	return &c
+}
+
+func (s *Spec) Ancestor(ancestor *datastore.Key) *Spec {
+	c := s.clone()
+	c.ancestor = ancestor
+
+	return c
+}
+
+func (s *Spec) Namespace(namespace string) *Spec {
+	c := s.clone()
+	c.namespace = namespace
+
+	return c
+}
+
+func (s *Spec) Filter(field, operator string, value any) *Spec {
+	return s.FilterEntity(datastore.PropertyFilter{FieldName: field, Operator: operator, Value: value})
+}
+
+func (s *Spec) FilterEntity(filter datastore.EntityFilter) *Spec {
+	c := s.clone()
+	c.filters = append(c.filters, filter)
+
+	return c
+}
+
+func (s *Spec) Order(fields ...string) *Spec {
+	c := s.clone()
+	c.orders = append(c.orders, fields...)
+
+	return c
+}
+
+func (s *Spec) Project(fields ...string) *Spec {
+	c := s.clone()
+	c.project = append(c.project, fields...)
+
+	return c
+}
+
+func (s *Spec) DistinctOn(fields ...string) *Spec {
+	c := s.clone()
+	c.distinctOn = append(c.distinctOn, fields...)
+
+	return c
+}
+
+func (s *Spec) Limit(limit int) *Spec {
+	c := s.clone()
+	c.limit = limit
+
+	return c
+}
+
+func (s *Spec) Offset(offset int) *Spec {
+	c := s.clone()
+	c.offset = offset
+
+	return c
+}
+
+func (s *Spec) Cursor(cursor string) *Spec {
+	c := s.clone()
+	c.cursor = cursor
+
+	return c
+}
+
+func (s *Spec) Values() SpecValues {
+	c := s.clone()
+
+	return SpecValues{
+		Ancestor:   c.ancestor,
+		Namespace:  c.namespace,
+		Filters:    c.filters,
+		Orders:     c.orders,
+		Project:    c.project,
+		DistinctOn: c.distinctOn,
+		Limit:      c.limit,
+		Offset:     c.offset,
+		Cursor:     c.cursor,
+	}
+}
+
+func (s *Spec) Build(kind string) (*datastore.Query, error) {
+	if kind == "" {
+		return nil, invalid("kind", "cannot be empty")
+	}
+
+	if s == nil {
+		s = &Spec{}
+	}
+
+	query := datastore.NewQuery(kind)
+
+	if s.ancestor != nil {
+		query = query.Ancestor(s.ancestor)
+	}
+
+	if s.namespace != "" {
+		query = query.Namespace(s.namespace)
+	}
+
+	for _, f := range s.filters {
+		if f == nil {
+			return nil, invalid("filter", "cannot be nil")
+		}
+
+		query = query.FilterEntity(f)
+	}
+
+	for _, o := range s.orders {
+		if strings.TrimPrefix(o, "-") == "" {
+			return nil, invalid("order field", "cannot be empty")
+		}
+
+		query = query.Order(o)
+	}
+
+	if len(s.project) > 0 {
+		if err := requiresFields(s.project); err != nil {
+			return nil, err
+		}
+
+		query = query.Project(s.project...)
+	}
+
+	if len(s.distinctOn) > 0 {
+		if len(s.project) == 0 {
+			return nil, invalid("distinct on", "requires projection fields")
+		}
+
+		if err := requiresFields(s.distinctOn); err != nil {
+			return nil, err
+		}
+
+		query = query.DistinctOn(s.distinctOn...)
+	}
+
+	if s.limit > 0 {
+		query = query.Limit(s.limit)
+	}
+
+	if s.offset > 0 {
+		query = query.Offset(s.offset)
+	}
+
+	if s.cursor != "" {
+		c, err := datastore.DecodeCursor(s.cursor)
+		if err != nil {
+			return nil, &Error{Kind: ErrInvalidArgument, Err: err}
+		}
+
+		query = query.Start(c)
+	}
+
+	return query, nil
+}
@@ -0,0 +1,34 @@
+package query_test
+
+import (
+	"errors"
+	"testing"
+
+	q "github.com/huysamen/dskit/query"
+)
+
+func TestSpecBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *q.Spec
+		wantErr bool
+	}{
+		{name: "empty spec", spec: q.NewSpec()},
+		{name: "distinct on projected fields", spec: q.NewSpec().Project("A", "B").DistinctOn("A")},
+		{name: "distinct on without projection", spec: q.NewSpec().DistinctOn("A"), wantErr: true},
+		{name: "empty projection field", spec: q.NewSpec().Project(""), wantErr: true},
+		{name: "empty distinct on field", spec: q.NewSpec().Project("A").DistinctOn(""), wantErr: true},
+		{name: "empty order field", spec: q.NewSpec().Order("-"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.spec.Build("Counter")
+
+			var argErr *q.ArgumentError
+			if got := errors.As(err, &argErr); got != tt.wantErr {
+				t.Fatalf("Build() error = %v, want argument error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
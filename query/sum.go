@@ -4,10 +4,21 @@ import (
 	"context"
 
 	"cloud.google.com/go/datastore"
-	"cloud.google.com/go/datastore/apiv1/datastorepb"
 )
 
-func SumForField(ctx context.Context, client Client, query *datastore.Query, field string) (float64, error) {
+func SumForField(ctx context.Context, client Client, query *datastore.Query, field string, opts ...ReadOption) (_ float64, err error) {
+	ctx, span := start(ctx, client, "SumForField")
+	defer func() { finish(span, err, nil) }()
+
+	txn, err := snapshot(ctx, client, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if txn != nil {
+		return SumForFieldTxn(ctx, txn, client, query, field)
+	}
+
 	s, err := SumForFields(ctx, client, query, field)
 	if err != nil {
 		return 0, err
@@ -16,7 +27,10 @@ func SumForField(ctx context.Context, client Client, query *datastore.Query, fie
 	return s[field], nil
 }
 
-func SumForFieldTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, field string) (float64, error) {
+func SumForFieldTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, field string) (_ float64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "SumForFieldTxn")
+	defer func() { finish(span, err, nil) }()
+
 	s, err := SumForFieldsTxn(ctx, txn, client, query, field)
 	if err != nil {
 		return 0, err
@@ -25,7 +39,10 @@ func SumForFieldTxn(ctx context.Context, txn Transaction, client Client, query *
 	return s[field], nil
 }
 
-func SumForFields(ctx context.Context, client Client, query *datastore.Query, fields ...string) (map[string]float64, error) {
+func SumForFields(ctx context.Context, client Client, query *datastore.Query, fields ...string) (_ map[string]float64, err error) {
+	ctx, span := start(ctx, client, "SumForFields")
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresClient(client); err != nil {
 		return nil, err
 	}
@@ -47,12 +64,14 @@ func SumForFields(ctx context.Context, client Client, query *datastore.Query, fi
 
 	r, err := client.Client().RunAggregationQuery(ctx, aq)
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	for _, f := range fields {
-		sv := r[f]
-		v := sv.(*datastorepb.Value)
+		v, err := aggregateValue(r, f)
+		if err != nil {
+			return nil, err
+		}
 
 		value, err := numericValue(v)
 		if err != nil {
@@ -65,7 +84,10 @@ func SumForFields(ctx context.Context, client Client, query *datastore.Query, fi
 	return sums, nil
 }
 
-func SumForFieldsTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, fields ...string) (map[string]float64, error) {
+func SumForFieldsTxn(ctx context.Context, txn Transaction, client Client, query *datastore.Query, fields ...string) (_ map[string]float64, err error) {
+	ctx, span := startTxn(ctx, txn, client, "SumForFieldsTxn")
+	defer func() { finish(span, err, nil) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return nil, err
 	}
@@ -91,12 +113,14 @@ func SumForFieldsTxn(ctx context.Context, txn Transaction, client Client, query
 
 	r, err := client.Client().RunAggregationQuery(ctx, aq)
 	if err != nil {
-		return nil, err
+		return nil, Classify(err)
 	}
 
 	for _, f := range fields {
-		sv := r[f]
-		v := sv.(*datastorepb.Value)
+		v, err := aggregateValue(r, f)
+		if err != nil {
+			return nil, err
+		}
 
 		value, err := numericValue(v)
 		if err != nil {
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type instrumented interface {
+	Telemetry() *telemetry.Telemetry
+}
+
+type contextual interface {
+	Context() context.Context
+}
+
+func telemetryOf(sources ...any) *telemetry.Telemetry {
+	for _, v := range sources {
+		if i, ok := v.(instrumented); ok {
+			if t := i.Telemetry(); t != nil {
+				return t
+			}
+		}
+	}
+
+	return telemetry.Global()
+}
+
+func txnContext(txn Transaction) context.Context {
+	if c, ok := txn.(contextual); ok {
+		if ctx := c.Context(); ctx != nil {
+			return ctx
+		}
+	}
+
+	return context.Background()
+}
+
+func start(ctx context.Context, client Client, operation string, attrs ...attribute.KeyValue) (context.Context, *telemetry.Span) {
+	return telemetryOf(client).Start(ctx, "query."+operation, attrs...)
+}
+
+func startTxn(ctx context.Context, txn Transaction, client Client, operation string, attrs ...attribute.KeyValue) (context.Context, *telemetry.Span) {
+	return telemetryOf(txn, client).Start(ctx, "query."+operation, attrs...)
+}
+
+func finish(span *telemetry.Span, err error, result any) {
+	span.End(err, ErrorClass(err), result)
+}
+
+func finishPage(span *telemetry.Span, err error, result any, cursor *datastore.Cursor) {
+	span.SetAttributes(telemetry.Cursor(cursor != nil))
+	finish(span, err, result)
+}
+
+func keyAttributes(keys ...*datastore.Key) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{telemetry.Keys(len(keys))}
+
+	for _, k := range keys {
+		if k != nil {
+			return append(attrs, telemetry.Kind(k.Kind))
+		}
+	}
+
+	return attrs
+}
@@ -6,7 +6,10 @@ import (
 	"cloud.google.com/go/datastore"
 )
 
-func Update[E any](ctx context.Context, client Client, key *datastore.Key, entity *E) (*datastore.Key, error) {
+func Update[E any](ctx context.Context, client Client, key *datastore.Key, entity *E) (out *datastore.Key, err error) {
+	ctx, span := start(ctx, client, "Update", keyAttributes(key)...)
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresClient(client); err != nil {
 		return nil, err
 	}
@@ -19,10 +22,13 @@ func Update[E any](ctx context.Context, client Client, key *datastore.Key, entit
 		return nil, err
 	}
 
-	return client.Client().Put(ctx, key, entity)
+	return mutateOne(ctx, client, datastore.NewUpdate(key, entity))
 }
 
-func UpdateTxn[E any](txn Transaction, key *datastore.Key, entity *E) (*datastore.PendingKey, error) {
+func UpdateTxn[E any](txn Transaction, key *datastore.Key, entity *E) (out *datastore.PendingKey, err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "UpdateTxn", keyAttributes(key)...)
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return nil, err
 	}
@@ -35,10 +41,13 @@ func UpdateTxn[E any](txn Transaction, key *datastore.Key, entity *E) (*datastor
 		return nil, err
 	}
 
-	return txn.Txn().Put(key, entity)
+	return mutateOneTxn(txn, datastore.NewUpdate(key, entity))
 }
 
-func UpdateMulti[E any](ctx context.Context, client Client, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error) {
+func UpdateMulti[E any](ctx context.Context, client Client, keys []*datastore.Key, entities []*E) (out []*datastore.Key, err error) {
+	ctx, span := start(ctx, client, "UpdateMulti", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresClient(client); err != nil {
 		return nil, err
 	}
@@ -55,10 +64,13 @@ func UpdateMulti[E any](ctx context.Context, client Client, keys []*datastore.Ke
 		return nil, err
 	}
 
-	return client.Client().PutMulti(ctx, keys, entities)
+	return mutateMulti(ctx, client, datastore.NewUpdate, keys, entities)
 }
 
-func UpdateMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
+func UpdateMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E) (out []*datastore.PendingKey, err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "UpdateMultiTxn", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
 	if err := requiresTransaction(txn); err != nil {
 		return nil, err
 	}
@@ -75,5 +87,5 @@ func UpdateMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E
 		return nil, err
 	}
 
-	return txn.Txn().PutMulti(keys, entities)
+	return mutateTxn(txn, mutations(datastore.NewUpdate, keys, entities)...)
 }
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+)
+
+func Upsert[E any](ctx context.Context, client Client, key *datastore.Key, entity *E) (out *datastore.Key, err error) {
+	ctx, span := start(ctx, client, "Upsert", keyAttributes(key)...)
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	if err := requiresKey(key); err != nil {
+		return nil, err
+	}
+
+	if err := requiresEntity(entity); err != nil {
+		return nil, err
+	}
+
+	return mutateOne(ctx, client, datastore.NewUpsert(key, entity))
+}
+
+func UpsertTxn[E any](txn Transaction, key *datastore.Key, entity *E) (out *datastore.PendingKey, err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "UpsertTxn", keyAttributes(key)...)
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresTransaction(txn); err != nil {
+		return nil, err
+	}
+
+	if err := requiresKey(key); err != nil {
+		return nil, err
+	}
+
+	if err := requiresEntity(entity); err != nil {
+		return nil, err
+	}
+
+	return mutateOneTxn(txn, datastore.NewUpsert(key, entity))
+}
+
+func UpsertMulti[E any](ctx context.Context, client Client, keys []*datastore.Key, entities []*E) (out []*datastore.Key, err error) {
+	ctx, span := start(ctx, client, "UpsertMulti", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresClient(client); err != nil {
+		return nil, err
+	}
+
+	if err := requiresEqualLength(keys, entities); err != nil {
+		return nil, err
+	}
+
+	if err := requiresKeys(keys); err != nil {
+		return nil, err
+	}
+
+	if err := requiresEntities(entities); err != nil {
+		return nil, err
+	}
+
+	return mutateMulti(ctx, client, datastore.NewUpsert, keys, entities)
+}
+
+func UpsertMultiTxn[E any](txn Transaction, keys []*datastore.Key, entities []*E) (out []*datastore.PendingKey, err error) {
+	_, span := startTxn(txnContext(txn), txn, nil, "UpsertMultiTxn", keyAttributes(keys...)...)
+	defer func() { finish(span, err, out) }()
+
+	if err := requiresTransaction(txn); err != nil {
+		return nil, err
+	}
+
+	if err := requiresEqualLength(keys, entities); err != nil {
+		return nil, err
+	}
+
+	if err := requiresKeys(keys); err != nil {
+		return nil, err
+	}
+
+	if err := requiresEntities(entities); err != nil {
+		return nil, err
+	}
+
+	return mutateTxn(txn, mutations(datastore.NewUpsert, keys, entities)...)
+}
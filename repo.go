@@ -2,13 +2,26 @@ package dskit
 
 import (
 	"context"
+	"time"
 
 	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
 	q "github.com/huysamen/dskit/query"
 )
 
 type Repo[E any] interface {
 	Client() Client
+	Kind() string
+	KeyFromID(id int64, parent *datastore.Key) *datastore.Key
+	KeyFromIDContext(ctx context.Context, id int64, parent *datastore.Key) *datastore.Key
+	KeyFromName(name string, parent *datastore.Key) *datastore.Key
+	KeyFromNameContext(ctx context.Context, name string, parent *datastore.Key) *datastore.Key
+	IncompleteKey(parent *datastore.Key) *datastore.Key
+	IncompleteKeyContext(ctx context.Context, parent *datastore.Key) *datastore.Key
+	ParseKey(encoded string) (*datastore.Key, error)
+	AllocateIDs(ctx context.Context, parent *datastore.Key, n int) ([]*datastore.Key, error)
+	ReserveIDs(ctx context.Context, keys []*datastore.Key) error
+	WithNamespace(namespace string) Repo[E]
 	Create(ctx context.Context, ancestor *datastore.Key, entity *E) (*datastore.Key, error)
 	CreateTxn(txn q.Transaction, ancestor *datastore.Key, entity *E) (*datastore.PendingKey, error)
 	CreateWithKey(ctx context.Context, key *datastore.Key, entity *E) (*datastore.Key, error)
@@ -17,10 +30,16 @@ type Repo[E any] interface {
 	CreateMultiTxn(txn q.Transaction, ancestor *datastore.Key, entities []*E) ([]*datastore.PendingKey, error)
 	CreateMultiWithKeys(ctx context.Context, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error)
 	CreateMultiWithKeysTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error)
+	CreateFutureTxn(txn Transaction, ancestor *datastore.Key, entity *E) (*FutureKey, error)
+	CreateMultiFutureTxn(txn Transaction, ancestor *datastore.Key, entities []*E) ([]*FutureKey, error)
 	Read(ctx context.Context, key *datastore.Key) (*E, error)
 	ReadTxn(txn q.Transaction, key *datastore.Key) (*E, error)
 	ReadMulti(ctx context.Context, keys []*datastore.Key) ([]*E, error)
 	ReadMultiTxn(txn q.Transaction, keys []*datastore.Key) ([]*E, error)
+	ReadMultiPartial(ctx context.Context, keys []*datastore.Key) ([]*E, []q.ReadStatus, error)
+	ReadMultiPartialTxn(txn q.Transaction, keys []*datastore.Key) ([]*E, []q.ReadStatus, error)
+	ReadMultiMap(ctx context.Context, keys []*datastore.Key) (map[string]*E, error)
+	ReadMultiMapTxn(txn q.Transaction, keys []*datastore.Key) (map[string]*E, error)
 	List(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error)
 	ListTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error)
 	ListPage(ctx context.Context, ancestor *datastore.Key, limit int, offset int) ([]*E, *datastore.Cursor, error)
@@ -31,469 +50,1041 @@ type Repo[E any] interface {
 	ListAllTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key) ([]*E, error)
 	ListAllKeys(ctx context.Context, ancestor *datastore.Key) ([]*datastore.Key, error)
 	ListAllKeysTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key) ([]*datastore.Key, error)
-	ListProjection(ctx context.Context, ancestor *datastore.Key, limit int, cursor string, generate q.Generator[any], fields ...string) ([]*any, error)
-	ListProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, generate q.Generator[any], fields ...string) ([]*any, error)
+	// Deprecated: use ProjectList.
+	ListProjection(ctx context.Context, ancestor *datastore.Key, limit int, cursor string, generate q.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error)
+	// Deprecated: use ProjectListTxn.
+	ListProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string, generate q.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error)
+	// Deprecated: use ProjectPage.
 	ListPageProjection(ctx context.Context, ancestor *datastore.Key, limit int, offset int, generate q.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error)
+	// Deprecated: use ProjectPageTxn.
 	ListPageProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, offset int, generate q.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error)
+	// Deprecated: use ProjectAll.
 	ListAllProjection(ctx context.Context, ancestor *datastore.Key, generate q.Generator[any], fields ...string) ([]*any, error)
+	// Deprecated: use ProjectAllTxn.
 	ListAllProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, generate q.Generator[any], fields ...string) ([]*any, error)
+	Find(ctx context.Context, spec *q.Spec) ([]*E, *datastore.Cursor, error)
+	FindTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) ([]*E, *datastore.Cursor, error)
+	FindKeys(ctx context.Context, spec *q.Spec) ([]*datastore.Key, *datastore.Cursor, error)
+	FindKeysTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) ([]*datastore.Key, *datastore.Cursor, error)
+	FindProjection(ctx context.Context, spec *q.Spec, fields ...string) ([]*datastore.Entity, *datastore.Cursor, error)
+	FindProjectionTxn(ctx context.Context, txn q.Transaction, spec *q.Spec, fields ...string) ([]*datastore.Entity, *datastore.Cursor, error)
+	Count(ctx context.Context, spec *q.Spec) (int64, error)
+	CountTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) (int64, error)
+	Exists(ctx context.Context, spec *q.Spec) (bool, error)
+	ExistsTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) (bool, error)
+	Explain(ctx context.Context, spec *q.Spec) (*q.Explanation, error)
+	ExplainAnalyze(ctx context.Context, spec *q.Spec) ([]*E, *q.Explanation, error)
+	ListIter(ctx context.Context, ancestor *datastore.Key, cursor string) *q.Iterator[E]
+	ListIterTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, cursor string) *q.Iterator[E]
+	ListKeysIter(ctx context.Context, ancestor *datastore.Key, cursor string) *q.Iterator[datastore.Key]
+	ListKeysIterTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, cursor string) *q.Iterator[datastore.Key]
+	FindIter(ctx context.Context, spec *q.Spec) *q.Iterator[E]
+	FindIterTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) *q.Iterator[E]
+	FindKeysIter(ctx context.Context, spec *q.Spec) *q.Iterator[datastore.Key]
+	FindKeysIterTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) *q.Iterator[datastore.Key]
 	Update(ctx context.Context, key *datastore.Key, entity *E) error
 	UpdateTxn(txn q.Transaction, key *datastore.Key, entity *E) error
 	UpdateMulti(ctx context.Context, keys []*datastore.Key, entities []*E) error
 	UpdateMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) error
+	Upsert(ctx context.Context, key *datastore.Key, entity *E) (*datastore.Key, error)
+	UpsertTxn(txn q.Transaction, key *datastore.Key, entity *E) (*datastore.PendingKey, error)
+	UpsertMulti(ctx context.Context, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error)
+	UpsertMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error)
+	Patch(ctx context.Context, key *datastore.Key, patch func(*E) error) (*E, error)
+	PatchTxn(txn q.Transaction, key *datastore.Key, patch func(*E) error) (*E, error)
+	PatchMulti(ctx context.Context, keys []*datastore.Key, patch func(*E) error) ([]*E, error)
+	PatchMultiTxn(txn q.Transaction, keys []*datastore.Key, patch func(*E) error) ([]*E, error)
+	PatchFields(ctx context.Context, key *datastore.Key, fields map[string]any) (*E, error)
+	PatchFieldsTxn(txn q.Transaction, key *datastore.Key, fields map[string]any) (*E, error)
 	Delete(ctx context.Context, key *datastore.Key) error
 	DeleteTxn(txn q.Transaction, key *datastore.Key) error
 	DeleteMulti(ctx context.Context, keys []*datastore.Key) error
 	DeleteMultiTxn(txn q.Transaction, keys []*datastore.Key) error
+	Restore(ctx context.Context, key *datastore.Key) error
+	RestoreTxn(txn q.Transaction, key *datastore.Key) error
+	ListDeleted(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error)
+	ListDeletedTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error)
+	Purge(ctx context.Context, olderThan time.Duration) (int, error)
+	BackfillDeletedAt(ctx context.Context) (int, error)
 }
 
 type CRUD[E any] = Repo[E]
 
 type repo[E any] struct {
-	client Client
-	kind   string
+	client      Client
+	kind        string
+	hooks       []Hooks[E]
+	clock       func() time.Time
+	timestamps  *timestamps
+	deletedAt   *field
+	version     *field
+	namespace   string
+	scoped      bool
+	preallocate bool
+	slowQuery   *slowQueryLog
+	mismatch    *mismatchPolicy
+	err         error
 }
 
-func NewCRUDRepo[E any](client Client, kind string) Repo[E] {
-	return &repo[E]{
+func NewCRUDRepo[E any](client Client, kind string, opts ...RepoOption[E]) Repo[E] {
+	r := &repo[E]{
 		client: client,
 		kind:   kind,
+		clock:  time.Now,
 	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.clock == nil {
+		r.clock = time.Now
+	}
+
+	if err := r.checkMismatchPolicy(); err != nil && r.err == nil {
+		r.err = err
+	}
+
+	return r
 }
 
 func (r *repo[E]) Client() Client {
 	return r.client
 }
 
-func (r *repo[E]) Create(ctx context.Context, ancestor *datastore.Key, entity *E) (*datastore.Key, error) {
-	return q.Create(ctx, r.client, datastore.IncompleteKey(r.kind, ancestor), entity)
+func (r *repo[E]) query(ctx context.Context, spec *q.Spec) (*datastore.Query, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	spec, err := r.scopeSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.deletedAt != nil {
+		spec = spec.FilterEntity(r.live())
+	}
+
+	return spec.Build(r.kind)
+}
+
+func (r *repo[E]) Create(ctx context.Context, ancestor *datastore.Key, entity *E) (out *datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "Create")
+	defer func() { end(span, err, out) }()
+
+	keys, err := r.createKeys(ctx, ancestor, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.prepareSave(ctx, nil, saveCreate, keys, entity); err != nil {
+		return nil, err
+	}
+
+	return q.Create(ctx, r.client, keys[0], entity)
 }
 
-func (r *repo[E]) CreateTxn(txn q.Transaction, ancestor *datastore.Key, entity *E) (*datastore.PendingKey, error) {
-	return q.CreateTxn(txn, datastore.IncompleteKey(r.kind, ancestor), entity)
+func (r *repo[E]) CreateTxn(txn q.Transaction, ancestor *datastore.Key, entity *E) (out *datastore.PendingKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "CreateTxn")
+	defer func() { end(span, err, out) }()
+
+	keys, err := r.createKeys(ctx, ancestor, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.prepareSave(ctx, txn, saveCreate, keys, entity); err != nil {
+		return nil, err
+	}
+
+	return q.CreateTxn(txn, keys[0], entity)
 }
 
-func (r *repo[E]) CreateWithKey(ctx context.Context, key *datastore.Key, entity *E) (*datastore.Key, error) {
+func (r *repo[E]) CreateWithKey(ctx context.Context, key *datastore.Key, entity *E) (out *datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "CreateWithKey", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	if err := r.prepareSave(ctx, nil, saveCreate, []*datastore.Key{key}, entity); err != nil {
+		return nil, err
+	}
+
 	return q.Create(ctx, r.client, key, entity)
 }
 
-func (r *repo[E]) CreateWithKeyTxn(txn q.Transaction, key *datastore.Key, entity *E) (*datastore.PendingKey, error) {
+func (r *repo[E]) CreateWithKeyTxn(txn q.Transaction, key *datastore.Key, entity *E) (out *datastore.PendingKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "CreateWithKeyTxn", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	if err := r.prepareSave(ctx, txn, saveCreate, []*datastore.Key{key}, entity); err != nil {
+		return nil, err
+	}
+
 	return q.CreateTxn(txn, key, entity)
 }
 
-func (r *repo[E]) CreateMulti(ctx context.Context, ancestor *datastore.Key, entities []*E) ([]*datastore.Key, error) {
+func (r *repo[E]) CreateMulti(ctx context.Context, ancestor *datastore.Key, entities []*E) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "CreateMulti", telemetry.Keys(len(entities)))
+	defer func() { end(span, err, out) }()
+
 	if len(entities) == 0 {
 		return make([]*datastore.Key, 0), nil
 	}
 
-	keys := make([]*datastore.Key, len(entities))
+	keys, err := r.createKeys(ctx, ancestor, len(entities))
+	if err != nil {
+		return nil, err
+	}
 
-	for i := range entities {
-		keys[i] = datastore.IncompleteKey(r.kind, ancestor)
+	if err := r.prepareSave(ctx, nil, saveCreate, keys, entities...); err != nil {
+		return nil, err
 	}
 
 	return q.CreateMulti(ctx, r.client, keys, entities)
 }
 
-func (r *repo[E]) CreateMultiTxn(txn q.Transaction, ancestor *datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
+func (r *repo[E]) CreateMultiTxn(txn q.Transaction, ancestor *datastore.Key, entities []*E) (out []*datastore.PendingKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "CreateMultiTxn", telemetry.Keys(len(entities)))
+	defer func() { end(span, err, out) }()
+
 	if len(entities) == 0 {
 		return make([]*datastore.PendingKey, 0), nil
 	}
 
-	keys := make([]*datastore.Key, len(entities))
+	keys, err := r.createKeys(ctx, ancestor, len(entities))
+	if err != nil {
+		return nil, err
+	}
 
-	for i := range entities {
-		keys[i] = datastore.IncompleteKey(r.kind, ancestor)
+	if err := r.prepareSave(ctx, txn, saveCreate, keys, entities...); err != nil {
+		return nil, err
 	}
 
 	return q.CreateMultiTxn(txn, keys, entities)
 }
 
-func (r *repo[E]) CreateMultiWithKeys(ctx context.Context, keys []*datastore.Key, entities []*E) ([]*datastore.Key, error) {
+func (r *repo[E]) CreateMultiWithKeys(ctx context.Context, keys []*datastore.Key, entities []*E) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "CreateMultiWithKeys", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
 	if len(keys) == 0 {
 		return make([]*datastore.Key, 0), nil
 	}
 
+	if err := r.prepareSave(ctx, nil, saveCreate, keys, entities...); err != nil {
+		return nil, err
+	}
+
 	return q.CreateMulti(ctx, r.client, keys, entities)
 }
 
-func (r *repo[E]) CreateMultiWithKeysTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) ([]*datastore.PendingKey, error) {
+func (r *repo[E]) CreateMultiWithKeysTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) (out []*datastore.PendingKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "CreateMultiWithKeysTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
 	if len(keys) == 0 {
 		return make([]*datastore.PendingKey, 0), nil
 	}
 
+	if err := r.prepareSave(ctx, txn, saveCreate, keys, entities...); err != nil {
+		return nil, err
+	}
+
 	return q.CreateMultiTxn(txn, keys, entities)
 }
 
-func (r *repo[E]) Read(ctx context.Context, key *datastore.Key) (*E, error) {
+func (r *repo[E]) CreateFutureTxn(txn Transaction, ancestor *datastore.Key, entity *E) (out *FutureKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "CreateFutureTxn")
+	defer func() { end(span, err, out) }()
+
+	tracker, err := keyTracker(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.preallocate {
+		pk, err := r.CreateTxn(txn, ancestor, entity)
+		if err != nil {
+			return nil, err
+		}
+
+		return tracker.Track(pk), nil
+	}
+
+	keys, err := r.createKeys(ctx, ancestor, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := r.CreateWithKeyTxn(txn, keys[0], entity)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracker.Track(pk).allocate(keys[0]), nil
+}
+
+func (r *repo[E]) CreateMultiFutureTxn(txn Transaction, ancestor *datastore.Key, entities []*E) (out []*FutureKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "CreateMultiFutureTxn", telemetry.Keys(len(entities)))
+	defer func() { end(span, err, out) }()
+
+	tracker, err := keyTracker(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.preallocate {
+		pks, err := r.CreateMultiTxn(txn, ancestor, entities)
+		if err != nil {
+			return nil, err
+		}
+
+		return TrackAll(tracker, pks), nil
+	}
+
+	keys, err := r.createKeys(ctx, ancestor, len(entities))
+	if err != nil {
+		return nil, err
+	}
+
+	pks, err := r.CreateMultiWithKeysTxn(txn, keys, entities)
+	if err != nil {
+		return nil, err
+	}
+
+	futures := TrackAll(tracker, pks)
+
+	for i, f := range futures {
+		f.allocate(keys[i])
+	}
+
+	return futures, nil
+}
+
+func (r *repo[E]) Read(ctx context.Context, key *datastore.Key) (out *E, err error) {
+	ctx, span := r.start(ctx, nil, "Read", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := r.checkNamespace(ctx, key); err != nil {
+		return nil, err
+	}
+
 	entity := new(E)
-	err := q.Read(ctx, r.client, key, entity)
 
-	return entity, err
+	if err := q.Read(ctx, r.client, key, entity); err != nil {
+		return entity, err
+	}
+
+	if r.deleted(entity) {
+		return new(E), notFound()
+	}
+
+	return entity, r.afterLoad(ctx, entity)
 }
 
-func (r *repo[E]) ReadTxn(txn q.Transaction, key *datastore.Key) (*E, error) {
+func (r *repo[E]) ReadTxn(txn q.Transaction, key *datastore.Key) (out *E, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "ReadTxn", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := r.checkNamespace(ctx, key); err != nil {
+		return nil, err
+	}
+
 	entity := new(E)
-	err := q.ReadTxn(txn, key, entity)
 
-	return entity, err
+	if err := q.ReadTxn(txn, key, entity); err != nil {
+		return entity, err
+	}
+
+	if r.deleted(entity) {
+		return new(E), notFound()
+	}
+
+	return entity, r.afterLoad(ctx, entity)
 }
 
-func (r *repo[E]) ReadMulti(ctx context.Context, keys []*datastore.Key) ([]*E, error) {
+func (r *repo[E]) ReadMulti(ctx context.Context, keys []*datastore.Key) (out []*E, err error) {
+	ctx, span := r.start(ctx, nil, "ReadMulti", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
 	if len(keys) == 0 {
 		return make([]*E, 0), nil
 	}
 
-	entities := make([]*E, len(keys))
-	for i := range entities {
-		entities[i] = new(E)
+	if r.err != nil {
+		return nil, r.err
 	}
 
-	if err := r.client.Client().GetMulti(ctx, keys, entities); err != nil {
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return nil, err
+	}
+
+	entities := newEntities[E](len(keys))
+
+	if err := q.ReadMulti(ctx, r.client, keys, entities); err != nil {
+		return nil, err
+	}
+
+	if err := r.hideDeleted(entities); err != nil {
+		return nil, err
+	}
+
+	if err := r.afterLoad(ctx, entities...); err != nil {
 		return nil, err
 	}
 
 	return entities, nil
 }
 
-func (r *repo[E]) ReadMultiTxn(txn q.Transaction, keys []*datastore.Key) ([]*E, error) {
+func (r *repo[E]) ReadMultiTxn(txn q.Transaction, keys []*datastore.Key) (out []*E, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "ReadMultiTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
 	if len(keys) == 0 {
 		return make([]*E, 0), nil
 	}
 
-	entities := make([]*E, len(keys))
-	for i := range entities {
-		entities[i] = new(E)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return nil, err
+	}
+
+	entities := newEntities[E](len(keys))
+
+	if err := q.ReadMultiTxn(txn, keys, entities); err != nil {
+		return nil, err
+	}
+
+	if err := r.hideDeleted(entities); err != nil {
+		return nil, err
 	}
 
-	if err := txn.Txn().GetMulti(keys, entities); err != nil {
+	if err := r.afterLoad(ctx, entities...); err != nil {
 		return nil, err
 	}
 
 	return entities, nil
 }
 
-func (r *repo[E]) List(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) ReadMultiPartial(ctx context.Context, keys []*datastore.Key) (out []*E, _ []q.ReadStatus, err error) {
+	ctx, span := r.start(ctx, nil, "ReadMultiPartial", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if len(keys) == 0 {
+		return make([]*E, 0), make([]q.ReadStatus, 0), nil
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	if r.err != nil {
+		return nil, nil, r.err
 	}
 
-	if cursor != "" {
-		c, err := datastore.DecodeCursor(cursor)
-		if err != nil {
-			return nil, nil, err
-		}
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return nil, nil, err
+	}
 
-		query = query.Start(c)
+	entities := newEntities[E](len(keys))
+
+	statuses, err := q.ReadMultiPartial(ctx, r.client, keys, entities)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.hideDeletedStatuses(entities, statuses)
+
+	entities = withHoles(entities, statuses)
+
+	if err := r.afterLoad(ctx, entities...); err != nil {
+		return nil, nil, err
 	}
 
-	return q.Query[E](ctx, r.client, query)
+	return entities, statuses, nil
 }
 
-func (r *repo[E]) ListTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*E, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) ReadMultiPartialTxn(txn q.Transaction, keys []*datastore.Key) (out []*E, _ []q.ReadStatus, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "ReadMultiPartialTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if len(keys) == 0 {
+		return make([]*E, 0), make([]q.ReadStatus, 0), nil
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	if r.err != nil {
+		return nil, nil, r.err
 	}
 
-	if cursor != "" {
-		c, err := datastore.DecodeCursor(cursor)
-		if err != nil {
-			return nil, nil, err
-		}
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return nil, nil, err
+	}
+
+	entities := newEntities[E](len(keys))
 
-		query = query.Start(c)
+	statuses, err := q.ReadMultiPartialTxn(txn, keys, entities)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return q.QueryTxn[E](ctx, txn, r.client, query)
-}
+	r.hideDeletedStatuses(entities, statuses)
 
-func (r *repo[E]) ListPage(ctx context.Context, ancestor *datastore.Key, limit int, offset int) ([]*E, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+	entities = withHoles(entities, statuses)
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if err := r.afterLoad(ctx, entities...); err != nil {
+		return nil, nil, err
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	return entities, statuses, nil
+}
+
+func (r *repo[E]) ReadMultiMap(ctx context.Context, keys []*datastore.Key) (out map[string]*E, err error) {
+	ctx, span := r.start(ctx, nil, "ReadMultiMap", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
+	entities, statuses, err := r.ReadMultiPartial(ctx, keys)
+	if err != nil {
+		return nil, err
 	}
 
-	if offset > 0 {
-		query = query.Offset(offset)
+	return byKey(entities, statuses), q.ReadFailures(statuses)
+}
+
+func (r *repo[E]) ReadMultiMapTxn(txn q.Transaction, keys []*datastore.Key) (out map[string]*E, err error) {
+	_, span := r.start(contextOf(txn), txn, "ReadMultiMapTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
+	entities, statuses, err := r.ReadMultiPartialTxn(txn, keys)
+	if err != nil {
+		return nil, err
 	}
 
-	return q.Query[E](ctx, r.client, query)
+	return byKey(entities, statuses), q.ReadFailures(statuses)
 }
 
-func (r *repo[E]) ListPageTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, offset int) ([]*E, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) List(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "List", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	return r.Find(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+}
+
+func (r *repo[E]) ListTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "ListTxn", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	return r.FindTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+}
+
+func (r *repo[E]) ListPage(ctx context.Context, ancestor *datastore.Key, limit int, offset int) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "ListPage")
+	defer func() { endPage(span, err, out, next) }()
+
+	return r.Find(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Offset(offset))
+}
+
+func (r *repo[E]) ListPageTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, offset int) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "ListPageTxn")
+	defer func() { endPage(span, err, out, next) }()
+
+	return r.FindTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Offset(offset))
+}
+
+func (r *repo[E]) ListKeys(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) (out []*datastore.Key, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "ListKeys", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	return r.FindKeys(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+}
+
+func (r *repo[E]) ListKeysTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) (out []*datastore.Key, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "ListKeysTxn", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	return r.FindKeysTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+}
+
+func (r *repo[E]) ListAll(ctx context.Context, ancestor *datastore.Key) (out []*E, err error) {
+	ctx, span := r.start(ctx, nil, "ListAll")
+	defer func() { end(span, err, out) }()
+
+	e, _, err := r.Find(ctx, q.NewSpec().Ancestor(ancestor))
+
+	return e, err
+}
+
+func (r *repo[E]) ListAllTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key) (out []*E, err error) {
+	ctx, span := r.start(ctx, txn, "ListAllTxn")
+	defer func() { end(span, err, out) }()
+
+	e, _, err := r.FindTxn(ctx, txn, q.NewSpec().Ancestor(ancestor))
+
+	return e, err
+}
+
+func (r *repo[E]) ListAllKeys(ctx context.Context, ancestor *datastore.Key) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "ListAllKeys")
+	defer func() { end(span, err, out) }()
+
+	keys, _, err := r.FindKeys(ctx, q.NewSpec().Ancestor(ancestor))
+
+	return keys, err
+}
+
+func (r *repo[E]) ListAllKeysTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, txn, "ListAllKeysTxn")
+	defer func() { end(span, err, out) }()
+
+	keys, _, err := r.FindKeysTxn(ctx, txn, q.NewSpec().Ancestor(ancestor))
+
+	return keys, err
+}
+
+func (r *repo[E]) Find(ctx context.Context, spec *q.Spec) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "Find")
+	defer func() { endPage(span, err, out, next) }()
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	started := time.Now()
+	defer func() { r.logSlow(ctx, nil, "Find", spec, query, started, err) }()
+
+	out, next, err = q.Query[E](ctx, r.client, query)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if offset > 0 {
-		query = query.Offset(offset)
+	if err := r.afterLoad(ctx, out...); err != nil {
+		return nil, nil, err
 	}
 
-	return q.QueryTxn[E](ctx, txn, r.client, query)
+	return out, next, nil
 }
 
-func (r *repo[E]) ListKeys(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) ([]*datastore.Key, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) FindTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "FindTxn")
+	defer func() { endPage(span, err, out, next) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	started := time.Now()
+	defer func() { r.logSlow(ctx, txn, "FindTxn", spec, query, started, err) }()
+
+	out, next, err = q.QueryTxn[E](ctx, txn, r.client, query)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if cursor != "" {
-		c, err := datastore.DecodeCursor(cursor)
-		if err != nil {
-			return nil, nil, err
-		}
+	if err := r.afterLoad(ctx, out...); err != nil {
+		return nil, nil, err
+	}
+
+	return out, next, nil
+}
 
-		query = query.Start(c)
+func (r *repo[E]) FindKeys(ctx context.Context, spec *q.Spec) (out []*datastore.Key, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "FindKeys")
+	defer func() { endPage(span, err, out, next) }()
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	started := time.Now()
+	defer func() { r.logSlow(ctx, nil, "FindKeys", spec, query, started, err) }()
+
 	return q.QueryKeys(ctx, r.client, query)
 }
 
-func (r *repo[E]) ListKeysTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) ([]*datastore.Key, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) FindKeysTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) (out []*datastore.Key, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "FindKeysTxn")
+	defer func() { endPage(span, err, out, next) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
+	started := time.Now()
+	defer func() { r.logSlow(ctx, txn, "FindKeysTxn", spec, query, started, err) }()
 
-	if cursor != "" {
-		c, err := datastore.DecodeCursor(cursor)
-		if err != nil {
-			return nil, nil, err
-		}
+	return q.QueryKeysTxn(ctx, txn, r.client, query)
+}
+
+func (r *repo[E]) Count(ctx context.Context, spec *q.Spec) (out int64, err error) {
+	ctx, span := r.start(ctx, nil, "Count")
+	defer func() { end(span, err, out) }()
 
-		query = query.Start(c)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return 0, err
 	}
 
-	return q.QueryKeysTxn(ctx, txn, r.client, query)
+	started := time.Now()
+	defer func() { r.logSlow(ctx, nil, "Count", spec, query, started, err) }()
+
+	return q.CountForQuery(ctx, r.client, query)
 }
 
-func (r *repo[E]) ListAll(ctx context.Context, ancestor *datastore.Key) ([]*E, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) CountTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) (out int64, err error) {
+	ctx, span := r.start(ctx, txn, "CountTxn")
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return 0, err
 	}
 
-	e, _, err := q.Query[E](ctx, r.client, query)
+	started := time.Now()
+	defer func() { r.logSlow(ctx, txn, "CountTxn", spec, query, started, err) }()
 
-	return e, err
+	return q.CountForQueryTxn(ctx, txn, r.client, query)
 }
 
-func (r *repo[E]) ListAllTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key) ([]*E, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) Exists(ctx context.Context, spec *q.Spec) (out bool, err error) {
+	ctx, span := r.start(ctx, nil, "Exists")
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return false, err
 	}
 
-	e, _, err := q.QueryTxn[E](ctx, txn, r.client, query)
+	started := time.Now()
+	defer func() { r.logSlow(ctx, nil, "Exists", spec, query, started, err) }()
 
-	return e, err
+	return q.ExistsForQuery(ctx, r.client, query)
 }
 
-func (r *repo[E]) ListAllKeys(ctx context.Context, ancestor *datastore.Key) ([]*datastore.Key, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) ExistsTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) (out bool, err error) {
+	ctx, span := r.start(ctx, txn, "ExistsTxn")
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return false, err
 	}
 
-	keys, _, err := q.QueryKeys(ctx, r.client, query)
+	started := time.Now()
+	defer func() { r.logSlow(ctx, txn, "ExistsTxn", spec, query, started, err) }()
 
-	return keys, err
+	return q.ExistsForQueryTxn(ctx, txn, r.client, query)
 }
 
-func (r *repo[E]) ListAllKeysTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key) ([]*datastore.Key, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) ListIter(ctx context.Context, ancestor *datastore.Key, cursor string) *q.Iterator[E] {
+	ctx, span := r.start(ctx, nil, "ListIter", telemetry.Cursor(cursor != ""))
+
+	return r.FindIter(ctx, q.NewSpec().Ancestor(ancestor).Cursor(cursor)).OnDone(ending(span))
+}
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+func (r *repo[E]) ListIterTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, cursor string) *q.Iterator[E] {
+	ctx, span := r.start(ctx, txn, "ListIterTxn", telemetry.Cursor(cursor != ""))
+
+	return r.FindIterTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Cursor(cursor)).OnDone(ending(span))
+}
+
+func (r *repo[E]) ListKeysIter(ctx context.Context, ancestor *datastore.Key, cursor string) *q.Iterator[datastore.Key] {
+	ctx, span := r.start(ctx, nil, "ListKeysIter", telemetry.Cursor(cursor != ""))
+
+	return r.FindKeysIter(ctx, q.NewSpec().Ancestor(ancestor).Cursor(cursor)).OnDone(ending(span))
+}
+
+func (r *repo[E]) ListKeysIterTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, cursor string) *q.Iterator[datastore.Key] {
+	ctx, span := r.start(ctx, txn, "ListKeysIterTxn", telemetry.Cursor(cursor != ""))
+
+	return r.FindKeysIterTxn(ctx, txn, q.NewSpec().Ancestor(ancestor).Cursor(cursor)).OnDone(ending(span))
+}
+
+func (r *repo[E]) FindIter(ctx context.Context, spec *q.Spec) *q.Iterator[E] {
+	ctx, span := r.start(ctx, nil, "FindIter")
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return q.FailedIterator[E](err).OnDone(ending(span))
 	}
 
-	keys, _, err := q.QueryKeysTxn(ctx, txn, r.client, query)
+	return q.Iterate[E](ctx, r.client, query).Inspect(r.loaded(ctx)).OnDone(ending(span))
+}
 
-	return keys, err
+func (r *repo[E]) FindIterTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) *q.Iterator[E] {
+	ctx, span := r.start(ctx, txn, "FindIterTxn")
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return q.FailedIterator[E](err).OnDone(ending(span))
+	}
+
+	return q.IterateTxn[E](ctx, txn, r.client, query).Inspect(r.loaded(ctx)).OnDone(ending(span))
 }
 
-func (r *repo[E]) ListProjection(ctx context.Context, ancestor *datastore.Key, limit int, cursor string, generate q.Generator[any], fields ...string) ([]*any, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) FindKeysIter(ctx context.Context, spec *q.Spec) *q.Iterator[datastore.Key] {
+	ctx, span := r.start(ctx, nil, "FindKeysIter")
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return q.FailedIterator[datastore.Key](err).OnDone(ending(span))
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	return q.IterateKeys(ctx, r.client, query).OnDone(ending(span))
+}
+
+func (r *repo[E]) FindKeysIterTxn(ctx context.Context, txn q.Transaction, spec *q.Spec) *q.Iterator[datastore.Key] {
+	ctx, span := r.start(ctx, txn, "FindKeysIterTxn")
+
+	query, err := r.query(ctx, spec)
+	if err != nil {
+		return q.FailedIterator[datastore.Key](err).OnDone(ending(span))
 	}
 
-	if cursor != "" {
-		c, err := datastore.DecodeCursor(cursor)
-		if err != nil {
-			return nil, err
-		}
+	return q.IterateKeysTxn(ctx, txn, r.client, query).OnDone(ending(span))
+}
+
+func (r *repo[E]) Update(ctx context.Context, key *datastore.Key, entity *E) (err error) {
+	ctx, span := r.start(ctx, nil, "Update", telemetry.Keys(1))
+	defer func() { end(span, err, nil) }()
+
+	if r.version != nil {
+		return r.updateVersioned(ctx, []*datastore.Key{key}, []*E{entity})
+	}
 
-		query = query.Start(c)
+	if err := r.prepareSave(ctx, nil, saveUpdate, []*datastore.Key{key}, entity); err != nil {
+		return err
 	}
 
-	e, _, err := q.Project(ctx, r.client, query, generate, fields...)
+	_, err = q.Update(ctx, r.client, key, entity)
 
-	return e, err
+	return err
 }
 
-func (r *repo[E]) ListProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, generate q.Generator[any], fields ...string) ([]*any, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) UpdateTxn(txn q.Transaction, key *datastore.Key, entity *E) (err error) {
+	ctx, span := r.start(contextOf(txn), txn, "UpdateTxn", telemetry.Keys(1))
+	defer func() { end(span, err, nil) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if r.version != nil {
+		return r.updateVersionedTxn(ctx, txn, []*datastore.Key{key}, []*E{entity})
 	}
 
-	e, _, err := q.ProjectTxn(ctx, txn, r.client, query, generate, fields...)
+	if err := r.prepareSave(ctx, txn, saveUpdate, []*datastore.Key{key}, entity); err != nil {
+		return err
+	}
 
-	return e, err
+	_, err = q.UpdateTxn(txn, key, entity)
+
+	return err
 }
 
-func (r *repo[E]) ListPageProjection(ctx context.Context, ancestor *datastore.Key, limit int, offset int, generate q.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) UpdateMulti(ctx context.Context, keys []*datastore.Key, entities []*E) (err error) {
+	ctx, span := r.start(ctx, nil, "UpdateMulti", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, nil) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if len(entities) == 0 {
+		return nil
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	if r.version != nil {
+		return r.updateVersioned(ctx, keys, entities)
 	}
 
-	if offset > 0 {
-		query = query.Offset(offset)
+	if err := r.prepareSave(ctx, nil, saveUpdate, keys, entities...); err != nil {
+		return err
 	}
 
-	e, c, err := q.Project(ctx, r.client, query, generate, fields...)
+	_, err = q.UpdateMulti(ctx, r.client, keys, entities)
 
-	return e, c, err
+	return err
 }
 
-func (r *repo[E]) ListPageProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, offset int, generate q.Generator[any], fields ...string) ([]*any, *datastore.Cursor, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) UpdateMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) (err error) {
+	ctx, span := r.start(contextOf(txn), txn, "UpdateMultiTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, nil) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if len(entities) == 0 {
+		return nil
 	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	if r.version != nil {
+		return r.updateVersionedTxn(ctx, txn, keys, entities)
 	}
 
-	if offset > 0 {
-		query = query.Offset(offset)
+	if err := r.prepareSave(ctx, txn, saveUpdate, keys, entities...); err != nil {
+		return err
 	}
 
-	e, c, err := q.ProjectTxn(ctx, txn, r.client, query, generate, fields...)
+	_, err = q.UpdateMultiTxn(txn, keys, entities)
 
-	return e, c, err
+	return err
 }
 
-func (r *repo[E]) ListAllProjection(ctx context.Context, ancestor *datastore.Key, generate q.Generator[any], fields ...string) ([]*any, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) Upsert(ctx context.Context, key *datastore.Key, entity *E) (out *datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "Upsert", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if err := r.prepareSave(ctx, nil, saveUpsert, []*datastore.Key{key}, entity); err != nil {
+		return nil, err
 	}
 
-	e, _, err := q.Project(ctx, r.client, query, generate, fields...)
-
-	return e, err
+	return q.Upsert(ctx, r.client, key, entity)
 }
 
-func (r *repo[E]) ListAllProjectionTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, generate q.Generator[any], fields ...string) ([]*any, error) {
-	query := datastore.NewQuery(r.kind)
+func (r *repo[E]) UpsertTxn(txn q.Transaction, key *datastore.Key, entity *E) (out *datastore.PendingKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "UpsertTxn", telemetry.Keys(1))
+	defer func() { end(span, err, out) }()
 
-	if ancestor != nil {
-		query = query.Ancestor(ancestor)
+	if err := r.prepareSave(ctx, txn, saveUpsert, []*datastore.Key{key}, entity); err != nil {
+		return nil, err
 	}
 
-	e, _, err := q.ProjectTxn(ctx, txn, r.client, query, generate, fields...)
-
-	return e, err
+	return q.UpsertTxn(txn, key, entity)
 }
 
-func (r *repo[E]) Update(ctx context.Context, key *datastore.Key, entity *E) error {
-	_, err := q.Update(ctx, r.client, key, entity)
+func (r *repo[E]) UpsertMulti(ctx context.Context, keys []*datastore.Key, entities []*E) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "UpsertMulti", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
 
-	return err
-}
+	if len(entities) == 0 {
+		return make([]*datastore.Key, 0), nil
+	}
 
-func (r *repo[E]) UpdateTxn(txn q.Transaction, key *datastore.Key, entity *E) error {
-	_, err := q.UpdateTxn(txn, key, entity)
+	if err := r.prepareSave(ctx, nil, saveUpsert, keys, entities...); err != nil {
+		return nil, err
+	}
 
-	return err
+	return q.UpsertMulti(ctx, r.client, keys, entities)
 }
 
-func (r *repo[E]) UpdateMulti(ctx context.Context, keys []*datastore.Key, entities []*E) error {
+func (r *repo[E]) UpsertMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) (out []*datastore.PendingKey, err error) {
+	ctx, span := r.start(contextOf(txn), txn, "UpsertMultiTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, out) }()
+
 	if len(entities) == 0 {
-		return nil
+		return make([]*datastore.PendingKey, 0), nil
 	}
 
-	_, err := q.UpdateMulti(ctx, r.client, keys, entities)
+	if err := r.prepareSave(ctx, txn, saveUpsert, keys, entities...); err != nil {
+		return nil, err
+	}
 
-	return err
+	return q.UpsertMultiTxn(txn, keys, entities)
 }
 
-func (r *repo[E]) UpdateMultiTxn(txn q.Transaction, keys []*datastore.Key, entities []*E) error {
-	if len(entities) == 0 {
-		return nil
+func (r *repo[E]) Delete(ctx context.Context, key *datastore.Key) (err error) {
+	ctx, span := r.start(ctx, nil, "Delete", telemetry.Keys(1))
+	defer func() { end(span, err, nil) }()
+
+	if r.err != nil {
+		return r.err
 	}
 
-	_, err := q.UpdateMultiTxn(txn, keys, entities)
+	if err := r.checkNamespace(ctx, key); err != nil {
+		return err
+	}
 
-	return err
-}
+	if err := r.beforeDelete(ctx, key); err != nil {
+		return err
+	}
 
-func (r *repo[E]) Delete(ctx context.Context, key *datastore.Key) error {
-	return q.Delete(ctx, r.client, key)
+	if r.deletedAt != nil {
+		if err := r.softDelete(ctx, key); err != nil {
+			return err
+		}
+	} else if err := q.Delete(ctx, r.client, key); err != nil {
+		return err
+	}
+
+	return r.afterDelete(ctx, key)
 }
 
-func (r *repo[E]) DeleteTxn(txn q.Transaction, key *datastore.Key) error {
-	return q.DeleteTxn(txn, key)
+func (r *repo[E]) DeleteTxn(txn q.Transaction, key *datastore.Key) (err error) {
+	ctx, span := r.start(contextOf(txn), txn, "DeleteTxn", telemetry.Keys(1))
+	defer func() { end(span, err, nil) }()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if err := r.checkNamespace(ctx, key); err != nil {
+		return err
+	}
+
+	if err := r.beforeDelete(ctx, key); err != nil {
+		return err
+	}
+
+	if r.deletedAt != nil {
+		if err := r.softDeleteTxn(ctx, txn, key); err != nil {
+			return err
+		}
+	} else if err := q.DeleteTxn(txn, key); err != nil {
+		return err
+	}
+
+	return r.afterDelete(ctx, key)
 }
 
-func (r *repo[E]) DeleteMulti(ctx context.Context, keys []*datastore.Key) error {
-	return q.DeleteMulti(ctx, r.client, keys)
+func (r *repo[E]) DeleteMulti(ctx context.Context, keys []*datastore.Key) (err error) {
+	ctx, span := r.start(ctx, nil, "DeleteMulti", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, nil) }()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return err
+	}
+
+	if err := r.beforeDelete(ctx, keys...); err != nil {
+		return err
+	}
+
+	if r.deletedAt != nil {
+		if err := r.softDelete(ctx, keys...); err != nil {
+			return err
+		}
+	} else if err := q.DeleteMulti(ctx, r.client, keys); err != nil {
+		return err
+	}
+
+	return r.afterDelete(ctx, keys...)
 }
 
-func (r *repo[E]) DeleteMultiTxn(txn q.Transaction, keys []*datastore.Key) error {
-	return q.DeleteMultiTxn(txn, keys)
+func (r *repo[E]) DeleteMultiTxn(txn q.Transaction, keys []*datastore.Key) (err error) {
+	ctx, span := r.start(contextOf(txn), txn, "DeleteMultiTxn", telemetry.Keys(len(keys)))
+	defer func() { end(span, err, nil) }()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return err
+	}
+
+	if err := r.beforeDelete(ctx, keys...); err != nil {
+		return err
+	}
+
+	if r.deletedAt != nil {
+		if err := r.softDeleteTxn(ctx, txn, keys...); err != nil {
+			return err
+		}
+	} else if err := q.DeleteMultiTxn(txn, keys); err != nil {
+		return err
+	}
+
+	return r.afterDelete(ctx, keys...)
 }
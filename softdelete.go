@@ -0,0 +1,493 @@
+package dskit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+)
+
+const (
+	tagDeletedAt          = "deletedAt"
+	maxMutationsPerCommit = 500
+)
+
+var ErrSoftDeleteDisabled = fmt.Errorf("%w: soft delete is not enabled", q.ErrInvalidArgument)
+
+type KeyPurger interface {
+	PurgeKeys(ctx context.Context, olderThan time.Duration) ([]*datastore.Key, error)
+}
+
+func newDeletedAt[E any]() (*field, error) {
+	deletedAt, err := taggedField[E](tagDeletedAt, timeType)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletedAt == nil {
+		return nil, &q.ArgumentError{Argument: "entity", Reason: `has no dskit:"deletedAt" field`}
+	}
+
+	return deletedAt, nil
+}
+
+func (r *repo[E]) live() datastore.EntityFilter {
+	var zero any = time.Time{}
+
+	if r.deletedAt.pointer {
+		zero = (*time.Time)(nil)
+	}
+
+	return datastore.PropertyFilter{FieldName: r.deletedAt.property, Operator: "=", Value: zero}
+}
+
+func (r *repo[E]) removed() datastore.EntityFilter {
+	return datastore.PropertyFilter{FieldName: r.deletedAt.property, Operator: ">", Value: time.Time{}}
+}
+
+func (r *repo[E]) deleted(entity *E) bool {
+	return r.deletedAt != nil && entity != nil && !r.deletedAt.time(entity).IsZero()
+}
+
+func notFound() error {
+	return q.Classify(datastore.ErrNoSuchEntity)
+}
+
+func (r *repo[E]) hideDeleted(entities []*E) error {
+	var errs datastore.MultiError
+
+	for i, e := range entities {
+		if !r.deleted(e) {
+			continue
+		}
+
+		if errs == nil {
+			errs = make(datastore.MultiError, len(entities))
+		}
+
+		errs[i] = notFound()
+	}
+
+	if errs == nil {
+		return nil
+	}
+
+	return &q.BatchError{MultiError: errs}
+}
+
+func (r *repo[E]) hideDeletedStatuses(entities []*E, statuses []q.ReadStatus) {
+	for i, s := range statuses {
+		if s.Loaded() && r.deleted(entities[i]) {
+			statuses[i].State = q.ReadMissing
+			statuses[i].Err = notFound()
+		}
+	}
+}
+
+func (r *repo[E]) setDeletedAtTxn(ctx context.Context, txn q.Transaction, keys []*datastore.Key, at time.Time) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if len(keys) > maxMutationsPerCommit {
+		return &q.ArgumentError{Argument: "keys", Reason: fmt.Sprintf("cannot change more than %d entities in one transaction", maxMutationsPerCommit)}
+	}
+
+	props := newPropertyLists(len(keys))
+
+	statuses, err := q.ReadMultiPartialTxn(txn, keys, props)
+	if err != nil {
+		return err
+	}
+
+	if err := q.ReadFailures(statuses); err != nil {
+		return err
+	}
+
+	var (
+		changed []*datastore.Key
+		updated []*datastore.PropertyList
+	)
+
+	now := r.now()
+
+	for i, s := range statuses {
+		if !s.Loaded() {
+			if at.IsZero() {
+				return s.Err
+			}
+
+			continue
+		}
+
+		deletedAt, _ := propertyValue(*props[i], r.deletedAt.property).(time.Time)
+		if !deletedAt.IsZero() == !at.IsZero() {
+			continue
+		}
+
+		r.markDeleted(props[i], at, now)
+
+		changed = append(changed, keys[i])
+		updated = append(updated, props[i])
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	_, err = q.UpdateMultiTxn(txn, changed, updated)
+
+	return err
+}
+
+func (r *repo[E]) markDeleted(props *datastore.PropertyList, at, now time.Time) {
+	setProperty(props, r.deletedAt.property, r.deletedAt.timeValue(at))
+
+	if r.timestamps != nil && r.timestamps.updated != nil {
+		setProperty(props, r.timestamps.updated.property, r.timestamps.updated.timeValue(now))
+	}
+
+	if r.version != nil {
+		version, _ := propertyValue(*props, r.version.property).(int64)
+		setProperty(props, r.version.property, version+1)
+	}
+}
+
+func newPropertyLists(n int) []*datastore.PropertyList {
+	props := make([]*datastore.PropertyList, n)
+
+	for i := range props {
+		props[i] = &datastore.PropertyList{}
+	}
+
+	return props
+}
+
+func propertyValue(props datastore.PropertyList, name string) any {
+	for _, p := range props {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+
+	return nil
+}
+
+func hasProperty(props datastore.PropertyList, name string) bool {
+	return slices.ContainsFunc(props, func(p datastore.Property) bool { return p.Name == name })
+}
+
+func setProperty(props *datastore.PropertyList, name string, value any) {
+	for i, p := range *props {
+		if p.Name == name {
+			(*props)[i].Value = value
+
+			return
+		}
+	}
+
+	*props = append(*props, datastore.Property{Name: name, Value: value})
+}
+
+func (r *repo[E]) setDeletedAt(ctx context.Context, keys []*datastore.Key, at time.Time) error {
+	for batch := range slices.Chunk(keys, maxMutationsPerCommit) {
+		_, err := r.client.RunInTransaction(ctx, func(txn Transaction) error {
+			return r.setDeletedAtTxn(ctx, txn, batch, at)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *repo[E]) softDelete(ctx context.Context, keys ...*datastore.Key) error {
+	return r.setDeletedAt(ctx, keys, r.now())
+}
+
+func (r *repo[E]) softDeleteTxn(ctx context.Context, txn q.Transaction, keys ...*datastore.Key) error {
+	return r.setDeletedAtTxn(ctx, txn, keys, r.now())
+}
+
+func (r *repo[E]) Restore(ctx context.Context, key *datastore.Key) (err error) {
+	ctx, span := r.start(ctx, nil, "Restore", telemetry.Keys(1))
+	defer func() { end(span, err, nil) }()
+
+	if err := r.softDeleteEnabled(); err != nil {
+		return err
+	}
+
+	if err := r.checkNamespace(ctx, key); err != nil {
+		return err
+	}
+
+	return r.setDeletedAt(ctx, []*datastore.Key{key}, time.Time{})
+}
+
+func (r *repo[E]) RestoreTxn(txn q.Transaction, key *datastore.Key) (err error) {
+	ctx, span := r.start(contextOf(txn), txn, "RestoreTxn", telemetry.Keys(1))
+	defer func() { end(span, err, nil) }()
+
+	if err := r.softDeleteEnabled(); err != nil {
+		return err
+	}
+
+	if err := r.checkNamespace(ctx, key); err != nil {
+		return err
+	}
+
+	return r.setDeletedAtTxn(ctx, txn, []*datastore.Key{key}, time.Time{})
+}
+
+func (r *repo[E]) ListDeleted(ctx context.Context, ancestor *datastore.Key, limit int, cursor string) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, nil, "ListDeleted", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	query, err := r.deletedQuery(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, next, err = q.Query[E](ctx, r.client, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.afterLoad(ctx, out...); err != nil {
+		return nil, nil, err
+	}
+
+	return out, next, nil
+}
+
+func (r *repo[E]) ListDeletedTxn(ctx context.Context, txn q.Transaction, ancestor *datastore.Key, limit int, cursor string) (out []*E, next *datastore.Cursor, err error) {
+	ctx, span := r.start(ctx, txn, "ListDeletedTxn", telemetry.Cursor(cursor != ""))
+	defer func() { endPage(span, err, out, next) }()
+
+	query, err := r.deletedQuery(ctx, q.NewSpec().Ancestor(ancestor).Limit(limit).Cursor(cursor))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, next, err = q.QueryTxn[E](ctx, txn, r.client, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.afterLoad(ctx, out...); err != nil {
+		return nil, nil, err
+	}
+
+	return out, next, nil
+}
+
+func (r *repo[E]) Purge(ctx context.Context, olderThan time.Duration) (out int, err error) {
+	ctx, span := r.start(ctx, nil, "Purge")
+	defer func() { end(span, err, out) }()
+
+	purged, err := r.purge(ctx, olderThan)
+
+	return len(purged), err
+}
+
+func (r *repo[E]) PurgeKeys(ctx context.Context, olderThan time.Duration) (out []*datastore.Key, err error) {
+	ctx, span := r.start(ctx, nil, "PurgeKeys")
+	defer func() { end(span, err, out) }()
+
+	return r.purge(ctx, olderThan)
+}
+
+func (r *repo[E]) purge(ctx context.Context, olderThan time.Duration) ([]*datastore.Key, error) {
+	if err := r.softDeleteEnabled(); err != nil {
+		return nil, err
+	}
+
+	cutoff := r.now().Add(-olderThan)
+
+	query, err := r.deletedQuery(ctx, q.NewSpec().Filter(r.deletedAt.property, "<", cutoff))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, _, err := q.QueryKeys(ctx, r.client, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*datastore.Key
+
+	for batch := range slices.Chunk(keys, maxMutationsPerCommit) {
+		var purged []*datastore.Key
+
+		_, err := r.client.RunInTransaction(ctx, func(txn Transaction) (err error) {
+			purged, err = r.purgeTxn(ctx, txn, batch, cutoff)
+
+			return err
+		})
+		if err != nil {
+			return out, err
+		}
+
+		out = append(out, purged...)
+
+		if err := r.afterDelete(ctx, purged...); err != nil {
+			return out, err
+		}
+	}
+
+	return out, nil
+}
+
+func (r *repo[E]) purgeTxn(ctx context.Context, txn q.Transaction, keys []*datastore.Key, cutoff time.Time) ([]*datastore.Key, error) {
+	entities := newEntities[E](len(keys))
+
+	statuses, err := q.ReadMultiPartialTxn(txn, keys, entities)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.ReadFailures(statuses); err != nil {
+		return nil, err
+	}
+
+	var purge []*datastore.Key
+
+	for i, s := range statuses {
+		if s.Loaded() && r.deleted(entities[i]) && r.deletedAt.time(entities[i]).Before(cutoff) {
+			purge = append(purge, keys[i])
+		}
+	}
+
+	if len(purge) == 0 {
+		return nil, nil
+	}
+
+	if err := r.beforeDelete(ctx, purge...); err != nil {
+		return nil, err
+	}
+
+	if err := q.DeleteMultiTxn(txn, purge); err != nil {
+		return nil, err
+	}
+
+	return purge, nil
+}
+
+func (r *repo[E]) BackfillDeletedAt(ctx context.Context) (out int, err error) {
+	ctx, span := r.start(ctx, nil, "BackfillDeletedAt")
+	defer func() { end(span, err, out) }()
+
+	if err := r.softDeleteEnabled(); err != nil {
+		return 0, err
+	}
+
+	spec, err := r.scopeSpec(ctx, q.NewSpec())
+	if err != nil {
+		return 0, err
+	}
+
+	query, err := spec.Build(r.kind)
+	if err != nil {
+		return 0, err
+	}
+
+	var missing []*datastore.Key
+
+	it := q.Iterate[datastore.PropertyList](ctx, r.client, query)
+
+	for k, props := range it.Keyed() {
+		if !hasProperty(*props, r.deletedAt.property) {
+			missing = append(missing, k)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+
+	for batch := range slices.Chunk(missing, maxMutationsPerCommit) {
+		var n int
+
+		_, err := r.client.RunInTransaction(ctx, func(txn Transaction) (err error) {
+			n, err = r.backfillTxn(txn, batch)
+
+			return err
+		})
+		if err != nil {
+			return out, err
+		}
+
+		out += n
+	}
+
+	return out, nil
+}
+
+func (r *repo[E]) backfillTxn(txn q.Transaction, keys []*datastore.Key) (int, error) {
+	props := newPropertyLists(len(keys))
+
+	statuses, err := q.ReadMultiPartialTxn(txn, keys, props)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := q.ReadFailures(statuses); err != nil {
+		return 0, err
+	}
+
+	var (
+		missing []*datastore.Key
+		updated []*datastore.PropertyList
+	)
+
+	for i, s := range statuses {
+		if !s.Loaded() || hasProperty(*props[i], r.deletedAt.property) {
+			continue
+		}
+
+		setProperty(props[i], r.deletedAt.property, r.deletedAt.timeValue(time.Time{}))
+
+		missing = append(missing, keys[i])
+		updated = append(updated, props[i])
+	}
+
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	if _, err := q.UpdateMultiTxn(txn, missing, updated); err != nil {
+		return 0, err
+	}
+
+	return len(missing), nil
+}
+
+func (r *repo[E]) softDeleteEnabled() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.deletedAt == nil {
+		return ErrSoftDeleteDisabled
+	}
+
+	return nil
+}
+
+func (r *repo[E]) deletedQuery(ctx context.Context, spec *q.Spec) (*datastore.Query, error) {
+	if err := r.softDeleteEnabled(); err != nil {
+		return nil, err
+	}
+
+	spec, err := r.scopeSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return spec.FilterEntity(r.removed()).Build(r.kind)
+}
@@ -0,0 +1,354 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type document struct {
+	Title     string
+	UpdatedAt time.Time  `dskit:"updatedAt"`
+	DeletedAt *time.Time `dskit:"deletedAt"`
+	Version   int64      `dskit:"version"`
+}
+
+type legacyDocument struct {
+	Title string
+}
+
+type annotatedDocument struct {
+	Title     string
+	Note      string
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+	Version   int64
+}
+
+type clock struct {
+	now atomic.Pointer[time.Time]
+}
+
+func newClock() *clock {
+	c := &clock{}
+	c.set(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	return c
+}
+
+func (c *clock) set(t time.Time) {
+	c.now.Store(&t)
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.set(c.now.Load().Add(d))
+}
+
+func (c *clock) Now() time.Time {
+	return *c.now.Load()
+}
+
+type deleteHooks struct {
+	saves, before, after atomic.Int64
+}
+
+func (h *deleteHooks) hooks() dskit.Hooks[document] {
+	return dskit.Hooks[document]{
+		BeforeSave:   func(context.Context, *document) error { h.saves.Add(1); return nil },
+		BeforeDelete: func(context.Context, *datastore.Key) error { h.before.Add(1); return nil },
+		AfterDelete:  func(context.Context, *datastore.Key) error { h.after.Add(1); return nil },
+	}
+}
+
+func newDocuments(t *testing.T, clk *clock, hooks *deleteHooks) (*fake.Client, dskit.Repo[document]) {
+	t.Helper()
+
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, fake.NewRepo[document](c, "Document",
+		dskit.WithSoftDelete[document](),
+		dskit.WithTimestamps[document](),
+		dskit.WithVersioning[document](),
+		dskit.WithClock[document](clk.Now),
+		dskit.WithHooks(hooks.hooks()),
+	)
+}
+
+func createDocuments(t *testing.T, r dskit.Repo[document], n int) []*datastore.Key {
+	t.Helper()
+
+	docs := make([]*document, n)
+	for i := range docs {
+		docs[i] = &document{Title: "doc"}
+	}
+
+	keys, err := r.CreateMulti(context.Background(), nil, docs)
+	if err != nil {
+		t.Fatalf("CreateMulti() error = %v", err)
+	}
+
+	return keys
+}
+
+func TestSoftDeleteLifecycle(t *testing.T) {
+	ctx := context.Background()
+	clk := newClock()
+	hooks := &deleteHooks{}
+	_, r := newDocuments(t, clk, hooks)
+
+	key := createDocuments(t, r, 1)[0]
+	saves := hooks.saves.Load()
+
+	clk.advance(time.Minute)
+
+	if err := r.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := r.Read(ctx, key); !errors.Is(err, q.ErrNotFound) {
+		t.Fatalf("Read() after soft delete error = %v, want ErrNotFound", err)
+	}
+
+	deleted, _, err := r.ListDeleted(ctx, nil, 10, "")
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("ListDeleted() = %d entities, %v, want 1", len(deleted), err)
+	}
+
+	if got := deleted[0]; !got.UpdatedAt.Equal(clk.Now()) || got.Version != 1 {
+		t.Fatalf("soft delete skipped the update path: updatedAt = %v, version = %d", got.UpdatedAt, got.Version)
+	}
+
+	if hooks.saves.Load() != saves {
+		t.Fatal("soft delete rewrote the entity through BeforeSave hooks")
+	}
+
+	if err := r.Restore(ctx, key); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	got, err := r.Read(ctx, key)
+	if err != nil || got.DeletedAt != nil || got.Version != 2 {
+		t.Fatalf("Read() after restore = %+v, %v", got, err)
+	}
+}
+
+func TestSoftDeleteBatches(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{name: "single commit", n: 3},
+		{name: "exactly one commit", n: 500},
+		{name: "several commits", n: 1201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r := newDocuments(t, newClock(), &deleteHooks{})
+			keys := createDocuments(t, r, tt.n)
+
+			if err := r.DeleteMulti(ctx, keys); err != nil {
+				t.Fatalf("DeleteMulti() error = %v", err)
+			}
+
+			n, err := r.Count(ctx, q.NewSpec())
+			if err != nil || n != 0 {
+				t.Fatalf("Count() after DeleteMulti = %d, %v, want 0", n, err)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteTxnLimit(t *testing.T) {
+	ctx := context.Background()
+	c, r := newDocuments(t, newClock(), &deleteHooks{})
+	keys := createDocuments(t, r, 501)
+
+	_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+		return r.DeleteMultiTxn(txn, keys)
+	})
+	if !errors.Is(err, q.ErrInvalidArgument) {
+		t.Fatalf("DeleteMultiTxn() of 501 keys error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	clk := newClock()
+	hooks := &deleteHooks{}
+	_, r := newDocuments(t, clk, hooks)
+
+	keys := createDocuments(t, r, 4)
+
+	if err := r.DeleteMulti(ctx, keys[:2]); err != nil {
+		t.Fatalf("DeleteMulti() error = %v", err)
+	}
+
+	clk.advance(2 * time.Hour)
+
+	if err := r.Delete(ctx, keys[2]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if err := r.Restore(ctx, keys[1]); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	before, after := hooks.before.Load(), hooks.after.Load()
+
+	n, err := r.Purge(ctx, time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("Purge() = %d, %v, want 1", n, err)
+	}
+
+	if got := hooks.before.Load() - before; got != 1 {
+		t.Fatalf("Purge() ran BeforeDelete %d times, want 1", got)
+	}
+
+	if got := hooks.after.Load() - after; got != 1 {
+		t.Fatalf("Purge() ran AfterDelete %d times, want 1", got)
+	}
+
+	deleted, _, err := r.ListDeleted(ctx, nil, 10, "")
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("ListDeleted() after purge = %d entities, %v, want 1", len(deleted), err)
+	}
+}
+
+func TestBackfillDeletedAt(t *testing.T) {
+	ctx := context.Background()
+	c, r := newDocuments(t, newClock(), &deleteHooks{})
+
+	legacy := fake.NewRepo[legacyDocument](c, "Document")
+	if _, err := legacy.CreateMulti(ctx, nil, []*legacyDocument{{Title: "a"}, {Title: "b"}}); err != nil {
+		t.Fatalf("CreateMulti() error = %v", err)
+	}
+
+	createDocuments(t, r, 1)
+
+	if n, err := r.Count(ctx, q.NewSpec()); err != nil || n != 1 {
+		t.Fatalf("Count() before backfill = %d, %v, want 1", n, err)
+	}
+
+	n, err := r.BackfillDeletedAt(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("BackfillDeletedAt() = %d, %v, want 2", n, err)
+	}
+
+	if n, err := r.Count(ctx, q.NewSpec()); err != nil || n != 3 {
+		t.Fatalf("Count() after backfill = %d, %v, want 3", n, err)
+	}
+}
+
+func TestSoftDeleteKeepsUnmappedProperties(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		mutate func(r dskit.Repo[document], key *datastore.Key) error
+	}{
+		{
+			name:   "Delete",
+			mutate: func(r dskit.Repo[document], key *datastore.Key) error { return r.Delete(ctx, key) },
+		},
+		{
+			name: "Delete and Restore",
+			mutate: func(r dskit.Repo[document], key *datastore.Key) error {
+				if err := r.Delete(ctx, key); err != nil {
+					return err
+				}
+
+				return r.Restore(ctx, key)
+			},
+		},
+		{
+			name: "BackfillDeletedAt",
+			mutate: func(r dskit.Repo[document], _ *datastore.Key) error {
+				_, err := r.BackfillDeletedAt(ctx)
+
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, r := newDocuments(t, newClock(), &deleteHooks{})
+			annotated := fake.NewRepo[annotatedDocument](c, "Document")
+
+			key, err := annotated.Create(ctx, nil, &annotatedDocument{Title: "doc", Note: "keep me"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if err := tt.mutate(r, key); err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+
+			got, err := annotated.Read(ctx, key)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if got.Note != "keep me" || got.Title != "doc" {
+				t.Fatalf("%s() dropped properties: %+v", tt.name, got)
+			}
+		})
+	}
+}
+
+func TestWriteKeepsSoftDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		write func(r dskit.Repo[document], key *datastore.Key, doc *document) error
+	}{
+		{
+			name:  "Update",
+			write: func(r dskit.Repo[document], key *datastore.Key, doc *document) error { return r.Update(ctx, key, doc) },
+		},
+		{
+			name: "Upsert",
+			write: func(r dskit.Repo[document], key *datastore.Key, doc *document) error {
+				_, err := r.Upsert(ctx, key, doc)
+
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r := newDocuments(t, newClock(), &deleteHooks{})
+			key := createDocuments(t, r, 1)[0]
+
+			if err := r.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			if err := tt.write(r, key, &document{Title: "rewritten", Version: 1}); err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+
+			if _, err := r.Read(ctx, key); !errors.Is(err, q.ErrNotFound) {
+				t.Fatalf("Read() after %s error = %v, want ErrNotFound", tt.name, err)
+			}
+
+			deleted, _, err := r.ListDeleted(ctx, nil, 10, "")
+			if err != nil || len(deleted) != 1 || deleted[0].Title != "rewritten" {
+				t.Fatalf("ListDeleted() = %v, %v, want the rewritten document", deleted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package dskit
+
+import (
+	"context"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type instrumented interface {
+	Telemetry() *telemetry.Telemetry
+}
+
+type contextual interface {
+	Context() context.Context
+}
+
+func telemetryOf(sources ...any) *telemetry.Telemetry {
+	for _, v := range sources {
+		if i, ok := v.(instrumented); ok {
+			if t := i.Telemetry(); t != nil {
+				return t
+			}
+		}
+	}
+
+	return telemetry.Global()
+}
+
+func contextOf(txn q.Transaction) context.Context {
+	if c, ok := txn.(contextual); ok {
+		if ctx := c.Context(); ctx != nil {
+			return ctx
+		}
+	}
+
+	return context.Background()
+}
+
+func (r *repo[E]) start(ctx context.Context, txn q.Transaction, operation string, attrs ...attribute.KeyValue) (context.Context, *telemetry.Span) {
+	return telemetryOf(txn, r.client).Start(telemetry.WithKind(r.withMismatchPolicy(ctx), r.kind), "repo."+operation, attrs...)
+}
+
+func end(span *telemetry.Span, err error, result any) {
+	span.End(err, q.ErrorClass(err), result)
+}
+
+func ending(span *telemetry.Span) func(err error) {
+	return func(err error) { end(span, err, nil) }
+}
+
+func endPage(span *telemetry.Span, err error, result any, cursor *datastore.Cursor) {
+	span.SetAttributes(telemetry.Cursor(cursor != nil))
+	end(span, err, result)
+}
@@ -0,0 +1,158 @@
+package dskit
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+const (
+	tagCreatedAt = "createdAt"
+	tagUpdatedAt = "updatedAt"
+)
+
+type saveOp int
+
+const (
+	saveCreate saveOp = iota
+	saveUpdate
+	saveUpsert
+)
+
+type timestamps struct {
+	created *field
+	updated *field
+}
+
+func newTimestamps[E any]() (*timestamps, error) {
+	created, err := taggedField[E](tagCreatedAt, timeType)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := taggedField[E](tagUpdatedAt, timeType)
+	if err != nil {
+		return nil, err
+	}
+
+	if created == nil && updated == nil {
+		return nil, &q.ArgumentError{Argument: "entity", Reason: `has no dskit:"createdAt" or dskit:"updatedAt" field`}
+	}
+
+	return &timestamps{created: created, updated: updated}, nil
+}
+
+func (r *repo[E]) now() time.Time {
+	return r.clock().Truncate(time.Microsecond)
+}
+
+func (r *repo[E]) stamp(entities []*E) {
+	ts := r.timestamps
+	if ts == nil {
+		return
+	}
+
+	now := r.now()
+
+	if ts.created != nil {
+		for _, e := range entities {
+			if e != nil && ts.created.time(e).IsZero() {
+				ts.created.setTime(e, now)
+			}
+		}
+	}
+
+	if ts.updated != nil {
+		for _, e := range entities {
+			if e != nil {
+				ts.updated.setTime(e, now)
+			}
+		}
+	}
+}
+
+func (r *repo[E]) preserve(ctx context.Context, txn q.Transaction, keys []*datastore.Key, entities []*E) error {
+	var created *field
+	if r.timestamps != nil {
+		created = r.timestamps.created
+	}
+
+	if created == nil && r.deletedAt == nil {
+		return nil
+	}
+
+	var (
+		positions []int
+		lookup    []*datastore.Key
+	)
+
+	for i, e := range entities {
+		if e == nil || i >= len(keys) || keys[i] == nil || keys[i].Incomplete() {
+			continue
+		}
+
+		positions = append(positions, i)
+		lookup = append(lookup, keys[i])
+	}
+
+	if len(lookup) == 0 {
+		return nil
+	}
+
+	stored := newEntities[E](len(lookup))
+
+	var (
+		statuses []q.ReadStatus
+		err      error
+	)
+
+	if txn != nil {
+		statuses, err = q.ReadMultiPartialTxn(txn, lookup, stored)
+	} else {
+		statuses, err = q.ReadMultiPartial(ctx, r.client, lookup, stored)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for j, s := range statuses {
+		if !s.Loaded() {
+			continue
+		}
+
+		e := entities[positions[j]]
+
+		if created != nil {
+			created.setTime(e, created.time(stored[j]))
+		}
+
+		if r.deletedAt != nil {
+			r.deletedAt.setTime(e, r.deletedAt.time(stored[j]))
+		}
+	}
+
+	return q.ReadFailures(statuses)
+}
+
+func (r *repo[E]) prepareSave(ctx context.Context, txn q.Transaction, op saveOp, keys []*datastore.Key, entities ...*E) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return err
+	}
+
+	if op != saveCreate {
+		if err := r.preserve(ctx, txn, keys, entities); err != nil {
+			return err
+		}
+	}
+
+	r.stamp(entities)
+
+	return r.beforeSave(ctx, entities...)
+}
@@ -0,0 +1,156 @@
+package dskit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+)
+
+type stampedNote struct {
+	Text      string
+	CreatedAt time.Time  `dskit:"createdAt"`
+	UpdatedAt *time.Time `dskit:"updatedAt"`
+}
+
+func TestTimestamps(t *testing.T) {
+	ctx := context.Background()
+	stale := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		n     int
+		write func(t *testing.T, c *fake.Client, r dskit.Repo[stampedNote], keys []*datastore.Key, notes []*stampedNote) error
+	}{
+		{name: "Create", n: 1},
+		{name: "CreateMulti", n: 3},
+		{
+			name: "Update",
+			n:    1,
+			write: func(_ *testing.T, _ *fake.Client, r dskit.Repo[stampedNote], keys []*datastore.Key, notes []*stampedNote) error {
+				return r.Update(ctx, keys[0], notes[0])
+			},
+		},
+		{
+			name: "UpdateMultiTxn",
+			n:    3,
+			write: func(_ *testing.T, c *fake.Client, r dskit.Repo[stampedNote], keys []*datastore.Key, notes []*stampedNote) error {
+				_, err := c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+					return r.UpdateMultiTxn(txn, keys, notes)
+				})
+
+				return err
+			},
+		},
+		{
+			name: "Upsert",
+			n:    1,
+			write: func(_ *testing.T, _ *fake.Client, r dskit.Repo[stampedNote], keys []*datastore.Key, notes []*stampedNote) error {
+				_, err := r.Upsert(ctx, keys[0], notes[0])
+
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClient()
+			t.Cleanup(func() { _ = c.Close() })
+
+			clk := newClock()
+			created := clk.Now()
+
+			r := fake.NewRepo[stampedNote](c, "Note", dskit.WithTimestamps[stampedNote](), dskit.WithClock[stampedNote](clk.Now))
+
+			notes := make([]*stampedNote, tt.n)
+			for i := range notes {
+				notes[i] = &stampedNote{Text: "first"}
+			}
+
+			var keys []*datastore.Key
+
+			if tt.n == 1 {
+				key, err := r.Create(ctx, nil, notes[0])
+				if err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+
+				keys = append(keys, key)
+			} else {
+				var err error
+
+				keys, err = r.CreateMulti(ctx, nil, notes)
+				if err != nil {
+					t.Fatalf("CreateMulti() error = %v", err)
+				}
+			}
+
+			for i, n := range notes {
+				if !n.CreatedAt.Equal(created) {
+					t.Fatalf("create stamped entity %d CreatedAt = %v, want %v", i, n.CreatedAt, created)
+				}
+			}
+
+			updated := created
+
+			if tt.write != nil {
+				clk.advance(time.Hour)
+				updated = clk.Now()
+
+				rewrites := make([]*stampedNote, tt.n)
+				for i := range rewrites {
+					rewrites[i] = &stampedNote{Text: "second", CreatedAt: stale}
+				}
+
+				if err := tt.write(t, c, r, keys, rewrites); err != nil {
+					t.Fatalf("%s() error = %v", tt.name, err)
+				}
+
+				for i, n := range rewrites {
+					if !n.CreatedAt.Equal(created) {
+						t.Fatalf("%s() entity %d CreatedAt = %v, want stored %v", tt.name, i, n.CreatedAt, created)
+					}
+				}
+			}
+
+			got, err := r.ReadMulti(ctx, keys)
+			if err != nil {
+				t.Fatalf("ReadMulti() error = %v", err)
+			}
+
+			for i, n := range got {
+				if !n.CreatedAt.Equal(created) {
+					t.Fatalf("entity %d CreatedAt = %v, want %v", i, n.CreatedAt, created)
+				}
+
+				if n.UpdatedAt == nil || !n.UpdatedAt.Equal(updated) {
+					t.Fatalf("entity %d UpdatedAt = %v, want %v", i, n.UpdatedAt, updated)
+				}
+			}
+		})
+	}
+}
+
+func TestTimestampsClockTruncation(t *testing.T) {
+	c := fake.NewClient()
+	t.Cleanup(func() { _ = c.Close() })
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	r := fake.NewRepo[stampedNote](c, "Note", dskit.WithTimestamps[stampedNote](), dskit.WithClock[stampedNote](func() time.Time { return now }))
+
+	note := &stampedNote{Text: "a"}
+
+	if _, err := r.Create(context.Background(), nil, note); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	want := now.Truncate(time.Microsecond)
+
+	if !note.CreatedAt.Equal(want) || note.UpdatedAt == nil || !note.UpdatedAt.Equal(want) {
+		t.Fatalf("Create() stamped %v / %v, want %v", note.CreatedAt, note.UpdatedAt, want)
+	}
+}
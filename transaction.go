@@ -1,6 +1,13 @@
 package dskit
 
-import "cloud.google.com/go/datastore"
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit/internal/telemetry"
+	q "github.com/huysamen/dskit/query"
+)
 
 type Transaction interface {
 	Txn() *datastore.Transaction
@@ -8,22 +15,93 @@ type Transaction interface {
 	Rollback() error
 }
 
+type KeyTracker interface {
+	Track(pending *datastore.PendingKey) *FutureKey
+}
+
+type CommitNotifier interface {
+	OnCommit(fn func())
+}
+
 type txn struct {
-	tx *datastore.Transaction
+	tx        *datastore.Transaction
+	keys      KeyRegistry
+	ctx       context.Context
+	telemetry *telemetry.Telemetry
+	mu        sync.Mutex
+	onCommit  []func()
 }
 
 func NewTransaction(tx *datastore.Transaction) Transaction {
-	return &txn{tx: tx}
+	return newTransaction(context.Background(), tx, telemetry.Global().With(telemetry.TransactionID()))
+}
+
+func newTransaction(ctx context.Context, tx *datastore.Transaction, t *telemetry.Telemetry) *txn {
+	return &txn{
+		tx:        tx,
+		ctx:       ctx,
+		telemetry: t,
+	}
 }
 
 func (t *txn) Txn() *datastore.Transaction {
 	return t.tx
 }
 
-func (t *txn) Commit() (*datastore.Commit, error) {
-	return t.tx.Commit()
+func (t *txn) Commit() (commit *datastore.Commit, err error) {
+	_, span := t.telemetry.Start(t.ctx, "transaction.Commit")
+	defer func() { span.End(err, q.ErrorClass(err), nil) }()
+
+	commit, err = t.tx.Commit()
+	if err != nil {
+		err = q.Classify(err)
+		t.keys.Fail(err)
+
+		return nil, err
+	}
+
+	t.keys.Resolve(commit.Key)
+
+	t.mu.Lock()
+	callbacks := t.onCommit
+	t.onCommit = nil
+	t.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+
+	return commit, nil
+}
+
+func (t *txn) Rollback() (err error) {
+	_, span := t.telemetry.Start(t.ctx, "transaction.Rollback")
+	defer func() { span.End(err, q.ErrorClass(err), nil) }()
+
+	t.keys.Fail(ErrRolledBack)
+
+	return q.Classify(t.tx.Rollback())
+}
+
+func (t *txn) Track(pending *datastore.PendingKey) *FutureKey {
+	return t.keys.Track(pending)
+}
+
+func (t *txn) OnCommit(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.onCommit = append(t.onCommit, fn)
+}
+
+func (t *txn) Context() context.Context {
+	return t.ctx
 }
 
-func (t *txn) Rollback() error {
-	return t.tx.Rollback()
+func (t *txn) Telemetry() *telemetry.Telemetry {
+	return t.telemetry
 }
@@ -0,0 +1,151 @@
+package dskit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	q "github.com/huysamen/dskit/query"
+)
+
+const tagVersion = "version"
+
+var ErrVersionConflict = fmt.Errorf("%w: version conflict", q.ErrConflict)
+
+type VersionConflictError struct {
+	Key      *datastore.Key
+	Expected int64
+	Actual   int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%v: key %v has version %d, expected %d", ErrVersionConflict, e.Key, e.Actual, e.Expected)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+func newVersion[E any]() (*field, error) {
+	version, err := taggedField[E](tagVersion, int64Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == nil {
+		return nil, &q.ArgumentError{Argument: "entity", Reason: `has no dskit:"version" field`}
+	}
+
+	return version, nil
+}
+
+func (r *repo[E]) checkVersions(ctx context.Context, txn q.Transaction, keys []*datastore.Key, entities []*E) error {
+	if len(keys) != len(entities) {
+		return &q.ArgumentError{Argument: "keys and entities", Reason: "must have the same length"}
+	}
+
+	for _, k := range keys {
+		if err := checkKind(k, r.kind); err != nil {
+			return err
+		}
+	}
+
+	if err := r.checkNamespace(ctx, keys...); err != nil {
+		return err
+	}
+
+	for _, e := range entities {
+		if e == nil {
+			return &q.ArgumentError{Argument: "entity pointer", Reason: "is nil"}
+		}
+	}
+
+	stored := newEntities[E](len(keys))
+
+	statuses, err := q.ReadMultiPartialTxn(txn, keys, stored)
+	if err != nil {
+		return err
+	}
+
+	if err := q.ReadFailures(statuses); err != nil {
+		return err
+	}
+
+	var conflicts []error
+
+	for i, s := range statuses {
+		if !s.Loaded() {
+			if len(keys) == 1 {
+				return s.Err
+			}
+
+			conflicts = append(conflicts, fmt.Errorf("key %v: %w", keys[i], s.Err))
+
+			continue
+		}
+
+		expected, actual := r.version.int64(entities[i]), r.version.int64(stored[i])
+		if expected != actual {
+			conflicts = append(conflicts, &VersionConflictError{Key: keys[i], Expected: expected, Actual: actual})
+		}
+	}
+
+	if len(conflicts) == 1 {
+		return conflicts[0]
+	}
+
+	if len(conflicts) > 0 {
+		return errors.Join(conflicts...)
+	}
+
+	return nil
+}
+
+func (r *repo[E]) updateVersionedTxn(ctx context.Context, txn q.Transaction, keys []*datastore.Key, entities []*E) error {
+	if err := r.checkVersions(ctx, txn, keys, entities); err != nil {
+		return err
+	}
+
+	next := make([]*E, len(entities))
+
+	for i, e := range entities {
+		c := *e
+		r.version.setInt64(&c, r.version.int64(e)+1)
+		next[i] = &c
+	}
+
+	if err := r.prepareSave(ctx, txn, saveUpdate, keys, next...); err != nil {
+		return err
+	}
+
+	if _, err := q.UpdateMultiTxn(txn, keys, next); err != nil {
+		return err
+	}
+
+	apply := func() {
+		for i, e := range entities {
+			*e = *next[i]
+		}
+	}
+
+	if n, ok := txn.(CommitNotifier); ok {
+		n.OnCommit(apply)
+	} else {
+		apply()
+	}
+
+	return nil
+}
+
+func (r *repo[E]) updateVersioned(ctx context.Context, keys []*datastore.Key, entities []*E) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	_, err := r.client.RunInTransaction(ctx, func(txn Transaction) error {
+		return r.updateVersionedTxn(ctx, txn, keys, entities)
+	})
+
+	return err
+}
@@ -0,0 +1,212 @@
+package dskit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/huysamen/dskit"
+	"github.com/huysamen/dskit/fake"
+	q "github.com/huysamen/dskit/query"
+)
+
+type account struct {
+	Balance int
+	Version int64 `dskit:"version"`
+}
+
+func newAccounts(t *testing.T, opts ...dskit.RepoOption[account]) (*fake.Client, dskit.Repo[account]) {
+	t.Helper()
+
+	policy := dskit.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = time.Millisecond
+
+	c := fake.NewClient(dskit.WithRetryPolicy(policy))
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, fake.NewRepo[account](c, "Account", append([]dskit.RepoOption[account]{dskit.WithVersioning[account]()}, opts...)...)
+}
+
+func TestVersionedUpdate(t *testing.T) {
+	ctx := context.Background()
+	errHook := errors.New("hook failed")
+
+	tests := []struct {
+		name        string
+		hook        error
+		stale       bool
+		wantErr     error
+		wantVersion int64
+		wantStored  int64
+	}{
+		{name: "success bumps the version", wantVersion: 1, wantStored: 1},
+		{name: "stale version conflicts", stale: true, wantErr: dskit.ErrVersionConflict, wantVersion: 0, wantStored: 1},
+		{name: "failed save keeps the version", hook: errHook, wantErr: errHook, wantVersion: 0, wantStored: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r := newAccounts(t, dskit.WithHooks(dskit.Hooks[account]{
+				BeforeSave: func(_ context.Context, a *account) error {
+					if a.Balance < 0 {
+						return tt.hook
+					}
+
+					return nil
+				},
+			}))
+
+			key, err := r.Create(ctx, nil, &account{Balance: 1})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if tt.stale {
+				if err := r.Update(ctx, key, &account{Balance: 2}); err != nil {
+					t.Fatalf("Update() error = %v", err)
+				}
+			}
+
+			e := &account{Balance: 3}
+			if tt.hook != nil {
+				e.Balance = -1
+			}
+
+			if err := r.Update(ctx, key, e); !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if e.Version != tt.wantVersion {
+				t.Fatalf("caller version = %d, want %d", e.Version, tt.wantVersion)
+			}
+
+			stored, err := r.Read(ctx, key)
+			if err != nil || stored.Version != tt.wantStored {
+				t.Fatalf("stored version = %d, %v, want %d", stored.Version, err, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestVersionedUpdateRetry(t *testing.T) {
+	ctx := context.Background()
+	c, r := newAccounts(t)
+
+	keys, err := r.CreateMulti(ctx, nil, []*account{{Balance: 1}, {Balance: 1}})
+	if err != nil {
+		t.Fatalf("CreateMulti() error = %v", err)
+	}
+
+	target, other := keys[0], keys[1]
+	e := &account{Balance: 10}
+	attempts := 0
+
+	_, err = c.RunInTransaction(ctx, func(txn dskit.Transaction) error {
+		attempts++
+
+		if _, err := r.ReadTxn(txn, other); err != nil {
+			return err
+		}
+
+		if attempts == 1 {
+			if _, err := r.Upsert(ctx, other, &account{Balance: 2}); err != nil {
+				return err
+			}
+		}
+
+		return r.UpdateTxn(txn, target, e)
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction() error = %v after %d attempts", err, attempts)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+
+	if e.Version != 1 {
+		t.Fatalf("caller version = %d, want 1", e.Version)
+	}
+
+	stored, err := r.Read(ctx, target)
+	if err != nil || stored.Version != 1 || stored.Balance != 10 {
+		t.Fatalf("Read() = %+v, %v, want balance 10 at version 1", stored, err)
+	}
+}
+
+func TestVersionedUpdateMultiConflict(t *testing.T) {
+	ctx := context.Background()
+	_, r := newAccounts(t)
+
+	keys, err := r.CreateMulti(ctx, nil, []*account{{}, {}})
+	if err != nil {
+		t.Fatalf("CreateMulti() error = %v", err)
+	}
+
+	if err := r.Update(ctx, keys[1], &account{Balance: 5}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	entities := []*account{{Balance: 1}, {Balance: 1}}
+
+	err = r.UpdateMulti(ctx, keys, entities)
+
+	var conflict *dskit.VersionConflictError
+	if !errors.As(err, &conflict) || !conflict.Key.Equal(keys[1]) {
+		t.Fatalf("UpdateMulti() error = %v, want a conflict on %v", err, keys[1])
+	}
+
+	for i, e := range entities {
+		if e.Version != 0 {
+			t.Fatalf("entity %d version = %d after a failed update", i, e.Version)
+		}
+	}
+}
+
+func TestVersionedUpdateChecksKeysFirst(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		scope string
+		key   func(key *datastore.Key) *datastore.Key
+	}{
+		{name: "nil key", key: func(*datastore.Key) *datastore.Key { return nil }},
+		{name: "wrong kind", key: func(key *datastore.Key) *datastore.Key { return datastore.IDKey("Ledger", key.ID, nil) }},
+		{
+			name:  "wrong namespace",
+			scope: "tenant-a",
+			key: func(key *datastore.Key) *datastore.Key {
+				return &datastore.Key{Kind: key.Kind, ID: key.ID, Namespace: "tenant-b"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, r := newAccounts(t)
+
+			key, err := r.Create(ctx, nil, &account{Balance: 1})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if tt.scope != "" {
+				r = r.WithNamespace(tt.scope)
+			}
+
+			lookups := c.Store().Calls("Lookup")
+
+			if err := r.Update(ctx, tt.key(key), &account{Balance: 2}); !errors.Is(err, q.ErrInvalidArgument) {
+				t.Fatalf("Update() error = %v, want %v", err, q.ErrInvalidArgument)
+			}
+
+			if got := c.Store().Calls("Lookup") - lookups; got != 0 {
+				t.Fatalf("Update() issued %d lookups before validating the key, want none", got)
+			}
+		})
+	}
+}